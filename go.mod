@@ -2,11 +2,13 @@ module go-hw-monitor
 
 go 1.25.4
 
-require github.com/shirou/gopsutil/v4 v4.25.10
+require (
+	github.com/gizak/termui/v3 v3.1.0
+	github.com/shirou/gopsutil/v4 v4.25.10
+)
 
 require (
 	github.com/ebitengine/purego v0.9.0 // indirect
-	github.com/gizak/termui/v3 v3.1.0 // indirect
 	github.com/go-ole/go-ole v1.2.6 // indirect
 	github.com/lufia/plan9stats v0.0.0-20211012122336-39d0f177ccd0 // indirect
 	github.com/mattn/go-runewidth v0.0.2 // indirect
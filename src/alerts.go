@@ -0,0 +1,505 @@
+// Package main provides threshold alerting for the hardware monitor.
+// This file contains alert rules such as "cpu > 80/95 for 30s", the metrics
+// they can refer to, and the AlertEngine that evaluates every snapshot.
+//
+// An alert fires once a rule's threshold has been crossed for the rule's
+// duration, escalates from warning to critical, and resolves only after the
+// value has moved back past the threshold by the hysteresis margin, so a
+// value hovering around the threshold doesn't flap between states.
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AlertLevel is the severity of an alert. Higher levels are more severe.
+type AlertLevel int
+
+const (
+	LevelNone AlertLevel = iota // Not alerting
+	LevelWarning
+	LevelCritical
+)
+
+// String returns the level name used in the UI and in JSON.
+func (l AlertLevel) String() string {
+	switch l {
+	case LevelWarning:
+		return "warning"
+	case LevelCritical:
+		return "critical"
+	default:
+		return "none"
+	}
+}
+
+// MarshalText writes the level by name, e.g. "critical", instead of a number.
+func (l AlertLevel) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText reads a level written by MarshalText, e.g. from a recording.
+func (l *AlertLevel) UnmarshalText(text []byte) error {
+	for _, level := range []AlertLevel{LevelNone, LevelWarning, LevelCritical} {
+		if string(text) == level.String() {
+			*l = level
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown alert level %q", text)
+}
+
+// AlertState says whether an alert is still firing.
+type AlertState string
+
+const (
+	AlertActive   AlertState = "active"   // The condition still holds
+	AlertResolved AlertState = "resolved" // The condition cleared (or the instance disappeared)
+)
+
+// Alert is one firing (or just resolved) rule for one instance of a metric.
+type Alert struct {
+	Rule       string     `json:"rule"`                 // The rule as written, normalized
+	Metric     string     `json:"metric"`               // Full metric name, e.g. "mounts.used_percent"
+	Instance   string     `json:"instance"`             // Which mount, core, device or interface; empty for single values
+	Level      AlertLevel `json:"level"`                // Severity at the time of the snapshot
+	State      AlertState `json:"state"`                // Active or resolved
+	Value      float64    `json:"value"`                // Latest value of the metric
+	Threshold  float64    `json:"threshold"`            // Threshold of Level that was crossed
+	Since      time.Time  `json:"since"`                // When the alert fired
+	ResolvedAt time.Time  `json:"resolved_at,omitzero"` // When it resolved, for resolved alerts
+}
+
+// Name returns the metric with its instance, e.g. "mounts.used_percent{/home}".
+func (a Alert) Name() string {
+	if a.Instance == "" {
+		return a.Metric
+	}
+	return a.Metric + "{" + a.Instance + "}"
+}
+
+// AlertRule is a parsed rule such as "cpu > 80/95 for 30s".
+type AlertRule struct {
+	Metric   string        // Full metric name, aliases already resolved
+	Op       string        // One of >, >=, <, <=
+	Warning  *float64      // Threshold for a warning, nil if the rule has none
+	Critical float64       // Threshold for a critical alert
+	For      time.Duration // How long a threshold must be crossed before the alert fires
+}
+
+// alertRulePattern matches "METRIC OP [WARNING/]CRITICAL [for DURATION]".
+var alertRulePattern = regexp.MustCompile(`^([a-z0-9_.]+)\s*(>=|<=|>|<)\s*(-?[0-9.]+)(?:\s*/\s*(-?[0-9.]+))?(?:\s+for\s+(\S+))?$`)
+
+// ParseAlertRule parses a rule. A single threshold raises critical alerts;
+// two thresholds separated by a slash are the warning and critical levels.
+//
+//	cpu > 95
+//	memory.used_percent > 85/95 for 30s
+//	memory.available_percent < 10/5 for 1m
+func ParseAlertRule(text string) (AlertRule, error) {
+	match := alertRulePattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(text)))
+	if match == nil {
+		return AlertRule{}, fmt.Errorf("invalid alert rule %q (expected e.g. \"cpu > 80/95 for 30s\")", text)
+	}
+
+	metric, ok := canonicalMetric(match[1])
+	if !ok {
+		return AlertRule{}, fmt.Errorf("alert rule %q: unknown metric %q (known: %s)",
+			text, match[1], strings.Join(sortedKeys(alertMetrics), ", "))
+	}
+	rule := AlertRule{Metric: metric, Op: match[2]}
+
+	first, err := strconv.ParseFloat(match[3], 64)
+	if err != nil {
+		return AlertRule{}, fmt.Errorf("alert rule %q: invalid threshold %q", text, match[3])
+	}
+	rule.Critical = first
+	if match[4] != "" {
+		critical, err := strconv.ParseFloat(match[4], 64)
+		if err != nil {
+			return AlertRule{}, fmt.Errorf("alert rule %q: invalid threshold %q", text, match[4])
+		}
+		// The warning must trigger before the critical level does
+		if first == critical || crosses(rule.Op, first, critical) {
+			return AlertRule{}, fmt.Errorf("alert rule %q: warning %s must come before critical %s", text, match[3], match[4])
+		}
+		rule.Warning, rule.Critical = &first, critical
+	}
+
+	if match[5] != "" {
+		rule.For, err = time.ParseDuration(match[5])
+		if err != nil || rule.For < 0 {
+			return AlertRule{}, fmt.Errorf("alert rule %q: invalid duration %q", text, match[5])
+		}
+	}
+	return rule, nil
+}
+
+// String returns the rule in its normalized form, e.g. "cpu.usage_percent > 80/95 for 30s".
+func (r AlertRule) String() string {
+	thresholds := formatThreshold(r.Critical)
+	if r.Warning != nil {
+		thresholds = formatThreshold(*r.Warning) + "/" + thresholds
+	}
+	text := fmt.Sprintf("%s %s %s", r.Metric, r.Op, thresholds)
+	if r.For > 0 {
+		text += " for " + r.For.String()
+	}
+	return text
+}
+
+// threshold returns the threshold for a level, and whether the rule has one.
+func (r AlertRule) threshold(level AlertLevel) (float64, bool) {
+	switch level {
+	case LevelCritical:
+		return r.Critical, true
+	case LevelWarning:
+		if r.Warning != nil {
+			return *r.Warning, true
+		}
+	}
+	return 0, false
+}
+
+// crosses reports whether value is past threshold in the direction of op.
+func crosses(op string, value, threshold float64) bool {
+	switch op {
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "<":
+		return value < threshold
+	default: // "<="
+		return value <= threshold
+	}
+}
+
+// clearThreshold moves a threshold back by the hysteresis margin, a fraction
+// of the threshold: with 5% a "> 90" alert stays active until the value
+// drops past 85.5, and a "< 10" alert until it rises past 10.5.
+func clearThreshold(op string, threshold, hysteresis float64) float64 {
+	margin := threshold * hysteresis
+	if margin < 0 {
+		margin = -margin
+	}
+	if op == "<" || op == "<=" {
+		return threshold + margin
+	}
+	return threshold - margin
+}
+
+// formatThreshold writes a threshold without trailing zeros, e.g. 85 or 0.5.
+func formatThreshold(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// alertSample is one value of a metric; instance tells several apart.
+type alertSample struct {
+	instance string
+	value    float64
+}
+
+// alertMetric is a value rules can refer to.
+type alertMetric struct {
+	collector string                                // Only evaluated when this collector succeeded
+	samples   func(stats SystemStats) []alertSample // Current values, one per instance
+}
+
+// single wraps one value as a sample list.
+func single(value float64) []alertSample {
+	return []alertSample{{value: value}}
+}
+
+// alertMetricAliases lets rules use the short names shown on the gauges.
+var alertMetricAliases = map[string]string{
+	"cpu":    "cpu.usage_percent",
+	"memory": "memory.used_percent",
+	"swap":   "swap.used_percent",
+	"disk":   "disk.used_percent",
+	"load":   "host.load_percent",
+}
+
+// canonicalMetric resolves an alias to the full metric name and reports
+// whether the metric exists.
+func canonicalMetric(name string) (string, bool) {
+	if full, ok := alertMetricAliases[name]; ok {
+		name = full
+	}
+	_, ok := alertMetrics[name]
+	return name, ok
+}
+
+// alertMetrics lists every metric rules can refer to, by full name.
+var alertMetrics = map[string]alertMetric{
+	"cpu.usage_percent": {"cpu", func(s SystemStats) []alertSample { return single(s.CPUUsage) }},
+	"cores.usage_percent": {"cores", func(s SystemStats) []alertSample {
+		samples := make([]alertSample, 0, len(s.CoreUsage))
+		for i, usage := range s.CoreUsage {
+			samples = append(samples, alertSample{strconv.Itoa(i), usage})
+		}
+		return samples
+	}},
+	"memory.used_percent": {"memory", func(s SystemStats) []alertSample { return single(s.MemoryUsage) }},
+	"memory.available_percent": {"memory", func(s SystemStats) []alertSample {
+		if s.MemoryDetail == nil || s.MemoryDetail.Total == 0 {
+			return nil
+		}
+		return single(float64(s.MemoryDetail.Available) / float64(s.MemoryDetail.Total) * 100)
+	}},
+	"swap.used_percent": {"memory", func(s SystemStats) []alertSample {
+		if s.SwapTotal == 0 {
+			return nil // No swap, nothing to fill up
+		}
+		return single(s.SwapUsage)
+	}},
+	"disk.used_percent": {"disk", func(s SystemStats) []alertSample {
+		return []alertSample{{s.DiskPath, s.DiskUsage}}
+	}},
+	"mounts.used_percent": {"mounts", func(s SystemStats) []alertSample {
+		samples := make([]alertSample, 0, len(s.Mounts))
+		for _, m := range s.Mounts {
+			samples = append(samples, alertSample{m.Path, m.UsedPercent})
+		}
+		return samples
+	}},
+	"diskio.util_percent": {"diskio", func(s SystemStats) []alertSample {
+		samples := make([]alertSample, 0, len(s.DiskIO))
+		for _, io := range s.DiskIO {
+			samples = append(samples, alertSample{io.Name, io.UtilPercent})
+		}
+		return samples
+	}},
+	"diskio.await_ms": {"diskio", func(s SystemStats) []alertSample {
+		samples := make([]alertSample, 0, len(s.DiskIO))
+		for _, io := range s.DiskIO {
+			samples = append(samples, alertSample{io.Name, io.AwaitMs})
+		}
+		return samples
+	}},
+	"network.rx_bytes_per_second": {"network", func(s SystemStats) []alertSample {
+		samples := make([]alertSample, 0, len(s.Network))
+		for _, n := range s.Network {
+			samples = append(samples, alertSample{n.Name, n.RxBytesPerSec})
+		}
+		return samples
+	}},
+	"network.tx_bytes_per_second": {"network", func(s SystemStats) []alertSample {
+		samples := make([]alertSample, 0, len(s.Network))
+		for _, n := range s.Network {
+			samples = append(samples, alertSample{n.Name, n.TxBytesPerSec})
+		}
+		return samples
+	}},
+	"host.load1":        {"host", hostSample(func(h *HostInfo) float64 { return h.Load1 })},
+	"host.load5":        {"host", hostSample(func(h *HostInfo) float64 { return h.Load5 })},
+	"host.load15":       {"host", hostSample(func(h *HostInfo) float64 { return h.Load15 })},
+	"host.load_percent": {"host", hostSample(func(h *HostInfo) float64 { return h.LoadPercent(h.Load1) })},
+}
+
+// hostSample builds the samples function for one value of the host info.
+func hostSample(value func(h *HostInfo) float64) func(SystemStats) []alertSample {
+	return func(s SystemStats) []alertSample {
+		if s.Host == nil {
+			return nil
+		}
+		return single(value(s.Host))
+	}
+}
+
+// alertKey identifies one instance of one rule.
+type alertKey struct {
+	rule     int
+	instance string
+}
+
+// alertTracker is the state of one instance of one rule between snapshots.
+type alertTracker struct {
+	alert   Alert                        // Current alert; Level is LevelNone while not firing
+	crossed [LevelCritical + 1]time.Time // When each level's threshold started being crossed, zero if it isn't
+}
+
+// AlertEngine evaluates rules against every snapshot and keeps track of
+// which alerts are active. It is used from the sampler goroutine only.
+type AlertEngine struct {
+	rules      []AlertRule
+	texts      []string // Normalized rule texts, for Alert.Rule
+	hysteresis float64  // Fraction of the threshold, e.g. 0.05
+	trackers   map[alertKey]*alertTracker
+}
+
+// NewAlertEngine parses rules and creates an engine for them.
+// hysteresisPercent is the margin, as a percentage of the threshold, by which
+// a value must move back before an alert resolves or de-escalates.
+func NewAlertEngine(rules []string, hysteresisPercent float64) (*AlertEngine, error) {
+	engine := &AlertEngine{
+		hysteresis: hysteresisPercent / 100,
+		trackers:   make(map[alertKey]*alertTracker),
+	}
+	for _, text := range rules {
+		rule, err := ParseAlertRule(text)
+		if err != nil {
+			return nil, err
+		}
+		engine.rules = append(engine.rules, rule)
+		engine.texts = append(engine.texts, rule.String())
+	}
+	return engine, nil
+}
+
+// Evaluate checks every rule against a snapshot and records the result in it:
+// stats.Alerts lists the active alerts, most severe first, and
+// stats.AlertEvents the alerts that fired, changed level or resolved.
+//
+// Rules whose collector failed are skipped and keep their previous state -
+// missing data is neither a breach nor a recovery.
+func (e *AlertEngine) Evaluate(stats *SystemStats) {
+	now := stats.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	var events []Alert
+	for i, rule := range e.rules {
+		metric := alertMetrics[rule.Metric]
+		if !stats.Succeeded(metric.collector) {
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, sample := range metric.samples(*stats) {
+			seen[sample.instance] = true
+			key := alertKey{i, sample.instance}
+			tracker := e.trackers[key]
+			if tracker == nil {
+				tracker = &alertTracker{alert: Alert{
+					Rule:     e.texts[i],
+					Metric:   rule.Metric,
+					Instance: sample.instance,
+				}}
+				e.trackers[key] = tracker
+			}
+			if event, changed := e.update(tracker, rule, sample.value, now); changed {
+				events = append(events, event)
+			}
+			if tracker.alert.Level == LevelNone && tracker.idle() {
+				delete(e.trackers, key) // Nothing to remember
+			}
+		}
+
+		// An instance that vanished (unmounted, interface removed) can't stay alerting
+		for key, tracker := range e.trackers {
+			if key.rule != i || seen[key.instance] {
+				continue
+			}
+			if tracker.alert.Level != LevelNone {
+				events = append(events, tracker.resolve(now))
+			}
+			delete(e.trackers, key)
+		}
+	}
+
+	stats.Alerts = e.active()
+	sortAlerts(events)
+	stats.AlertEvents = events
+}
+
+// update feeds one value into a tracker. It returns the alert and true if the
+// alert fired, changed level or resolved.
+func (e *AlertEngine) update(t *alertTracker, rule AlertRule, value float64, now time.Time) (Alert, bool) {
+	t.alert.Value = value
+	current := t.alert.Level
+
+	// Find the most severe level that holds: a level that is already active
+	// holds until the value moves back past the hysteresis margin, a new one
+	// must be crossed continuously for the rule's duration
+	target := LevelNone
+	for level := LevelCritical; level > LevelNone; level-- {
+		threshold, ok := rule.threshold(level)
+		if !ok {
+			continue
+		}
+		limit := threshold
+		if current >= level {
+			limit = clearThreshold(rule.Op, threshold, e.hysteresis)
+		}
+		if !crosses(rule.Op, value, limit) {
+			t.crossed[level] = time.Time{}
+			continue
+		}
+		if t.crossed[level].IsZero() {
+			t.crossed[level] = now
+		}
+		if target == LevelNone && (current >= level || now.Sub(t.crossed[level]) >= rule.For) {
+			target = level
+		}
+	}
+
+	switch {
+	case target == current:
+		return Alert{}, false
+	case target == LevelNone:
+		return t.resolve(now), true
+	}
+	if current == LevelNone {
+		t.alert.Since = now
+	}
+	t.alert.Level = target
+	t.alert.Threshold, _ = rule.threshold(target)
+	t.alert.State = AlertActive
+	return t.alert, true
+}
+
+// resolve ends the tracker's alert and returns it in its resolved state.
+func (t *alertTracker) resolve(now time.Time) Alert {
+	resolved := t.alert
+	resolved.State = AlertResolved
+	resolved.ResolvedAt = now
+
+	t.alert.Level = LevelNone
+	t.alert.State = ""
+	t.alert.Since = time.Time{}
+	return resolved
+}
+
+// idle reports whether no threshold is currently crossed.
+func (t *alertTracker) idle() bool {
+	for _, since := range t.crossed {
+		if !since.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+// active returns every firing alert, most severe first.
+func (e *AlertEngine) active() []Alert {
+	var alerts []Alert
+	for _, tracker := range e.trackers {
+		if tracker.alert.Level != LevelNone {
+			alerts = append(alerts, tracker.alert)
+		}
+	}
+	sortAlerts(alerts)
+	return alerts
+}
+
+// sortAlerts orders alerts most severe first, then oldest first, then by name,
+// so the banner doesn't reshuffle between snapshots.
+func sortAlerts(alerts []Alert) {
+	sort.SliceStable(alerts, func(i, j int) bool {
+		a, b := alerts[i], alerts[j]
+		if a.Level != b.Level {
+			return a.Level > b.Level
+		}
+		if !a.Since.Equal(b.Since) {
+			return a.Since.Before(b.Since)
+		}
+		return a.Name() < b.Name()
+	})
+}
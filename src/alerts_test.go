@@ -0,0 +1,208 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAlertRule(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		tests := []struct {
+			text string
+			want string // Normalized form
+		}{
+			{text: "cpu > 95", want: "cpu.usage_percent > 95"},
+			{text: "Memory>85/95 for 30s", want: "memory.used_percent > 85/95 for 30s"},
+			{text: "memory.available_percent < 10/5 for 1m", want: "memory.available_percent < 10/5 for 1m0s"},
+			{text: "  mounts.used_percent >= 90.5  ", want: "mounts.used_percent >= 90.5"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.text, func(t *testing.T) {
+				rule, err := ParseAlertRule(tt.text)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if rule.String() != tt.want {
+					t.Errorf("Expected %q, got %q", tt.want, rule.String())
+				}
+			})
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		tests := []struct {
+			name string
+			text string
+			want string // Part of the error message
+		}{
+			{name: "Syntax", text: "cpu is high", want: "invalid alert rule"},
+			{name: "UnknownMetric", text: "gpu > 90", want: `unknown metric "gpu"`},
+			{name: "WarningAfterCritical", text: "cpu > 95/80", want: "must come before critical"},
+			{name: "WarningAfterCriticalBelow", text: "memory.available_percent < 5/10", want: "must come before critical"},
+			{name: "SameLevels", text: "cpu > 90/90", want: "must come before critical"},
+			{name: "BadThreshold", text: "cpu > 9.0.1", want: "invalid threshold"},
+			{name: "BadDuration", text: "cpu > 90 for soon", want: "invalid duration"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := ParseAlertRule(tt.text)
+				if err == nil || !strings.Contains(err.Error(), tt.want) {
+					t.Errorf("Expected error containing %q, got %v", tt.want, err)
+				}
+			})
+		}
+	})
+}
+
+// cpuSnapshot returns a snapshot with a successful CPU reading at the given time.
+func cpuSnapshot(at time.Time, cpu float64) SystemStats {
+	stats := SystemStats{Time: at, CPUUsage: cpu}
+	stats.setStatus("cpu", nil)
+	return stats
+}
+
+// evaluate runs the engine on a snapshot and returns it with its alerts.
+func evaluate(engine *AlertEngine, stats SystemStats) SystemStats {
+	engine.Evaluate(&stats)
+	return stats
+}
+
+func TestAlertEngine(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	at := func(seconds int) time.Time { return start.Add(time.Duration(seconds) * time.Second) }
+
+	t.Run("FiresAfterDuration", func(t *testing.T) {
+		// Arrange
+		engine, err := NewAlertEngine([]string{"cpu > 90 for 30s"}, 5)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		// Act & Assert - crossed, but not for long enough yet
+		for _, seconds := range []int{0, 20} {
+			if stats := evaluate(engine, cpuSnapshot(at(seconds), 95)); len(stats.Alerts) != 0 || len(stats.AlertEvents) != 0 {
+				t.Fatalf("Expected no alert after %ds, got %+v", seconds, stats.Alerts)
+			}
+		}
+
+		stats := evaluate(engine, cpuSnapshot(at(30), 96))
+		if len(stats.Alerts) != 1 || len(stats.AlertEvents) != 1 {
+			t.Fatalf("Expected one alert firing after 30s, got %+v / %+v", stats.Alerts, stats.AlertEvents)
+		}
+		alert := stats.Alerts[0]
+		if alert.Level != LevelCritical || alert.State != AlertActive || alert.Value != 96 || alert.Threshold != 90 {
+			t.Errorf("Unexpected alert %+v", alert)
+		}
+		if !alert.Since.Equal(at(30)) || alert.Rule != "cpu.usage_percent > 90 for 30s" {
+			t.Errorf("Expected rule and firing time to be recorded, got %+v", alert)
+		}
+
+		// Still firing - listed, but no new event
+		stats = evaluate(engine, cpuSnapshot(at(31), 97))
+		if len(stats.Alerts) != 1 || len(stats.AlertEvents) != 0 {
+			t.Errorf("Expected an ongoing alert without events, got %+v / %+v", stats.Alerts, stats.AlertEvents)
+		}
+	})
+
+	t.Run("DipResetsDuration", func(t *testing.T) {
+		engine, _ := NewAlertEngine([]string{"cpu > 90 for 30s"}, 5)
+		evaluate(engine, cpuSnapshot(at(0), 95))
+		evaluate(engine, cpuSnapshot(at(20), 50)) // Dip - the clock starts over
+		evaluate(engine, cpuSnapshot(at(25), 95))
+
+		if stats := evaluate(engine, cpuSnapshot(at(40), 95)); len(stats.Alerts) != 0 {
+			t.Errorf("Expected no alert 15s after the dip, got %+v", stats.Alerts)
+		}
+	})
+
+	t.Run("Hysteresis", func(t *testing.T) {
+		// Arrange - with 5% the alert clears below 85.5
+		engine, _ := NewAlertEngine([]string{"cpu > 90"}, 5)
+		evaluate(engine, cpuSnapshot(at(0), 95))
+
+		// Act & Assert - hovering just under the threshold keeps it active
+		if stats := evaluate(engine, cpuSnapshot(at(1), 88)); len(stats.Alerts) != 1 || len(stats.AlertEvents) != 0 {
+			t.Fatalf("Expected the alert to stay active at 88, got %+v", stats.Alerts)
+		}
+		stats := evaluate(engine, cpuSnapshot(at(2), 85))
+		if len(stats.Alerts) != 0 || len(stats.AlertEvents) != 1 {
+			t.Fatalf("Expected the alert to resolve at 85, got %+v / %+v", stats.Alerts, stats.AlertEvents)
+		}
+		resolved := stats.AlertEvents[0]
+		if resolved.State != AlertResolved || !resolved.ResolvedAt.Equal(at(2)) || !resolved.Since.Equal(at(0)) {
+			t.Errorf("Unexpected resolved event %+v", resolved)
+		}
+	})
+
+	t.Run("Levels", func(t *testing.T) {
+		engine, _ := NewAlertEngine([]string{"cpu > 80/90"}, 5)
+
+		steps := []struct {
+			cpu       float64
+			wantLevel AlertLevel // LevelNone means no active alert
+			wantEvent bool
+		}{
+			{cpu: 85, wantLevel: LevelWarning, wantEvent: true},
+			{cpu: 95, wantLevel: LevelCritical, wantEvent: true}, // Escalates
+			{cpu: 87, wantLevel: LevelCritical},                  // Within the critical margin
+			{cpu: 84, wantLevel: LevelWarning, wantEvent: true},  // De-escalates
+			{cpu: 70, wantLevel: LevelNone, wantEvent: true},     // Resolves
+		}
+		for i, step := range steps {
+			stats := evaluate(engine, cpuSnapshot(at(i), step.cpu))
+
+			level := LevelNone
+			if len(stats.Alerts) > 0 {
+				level = stats.Alerts[0].Level
+			}
+			if level != step.wantLevel {
+				t.Errorf("Step %d (cpu %.0f): expected level %s, got %s", i, step.cpu, step.wantLevel, level)
+			}
+			if (len(stats.AlertEvents) > 0) != step.wantEvent {
+				t.Errorf("Step %d (cpu %.0f): expected event %v, got %+v", i, step.cpu, step.wantEvent, stats.AlertEvents)
+			}
+		}
+	})
+
+	t.Run("FailedCollectorKeepsState", func(t *testing.T) {
+		engine, _ := NewAlertEngine([]string{"cpu > 90"}, 5)
+		evaluate(engine, cpuSnapshot(at(0), 95))
+
+		// The CPU collector fails: its 0 must not resolve the alert
+		failed := SystemStats{Time: at(1)}
+		failed.setStatus("cpu", errors.New("cpu error"))
+		stats := evaluate(engine, failed)
+
+		if len(stats.Alerts) != 1 || len(stats.AlertEvents) != 0 {
+			t.Errorf("Expected the alert to stay active without events, got %+v / %+v", stats.Alerts, stats.AlertEvents)
+		}
+	})
+
+	t.Run("Instances", func(t *testing.T) {
+		// Arrange - two full mounts, then one is unmounted
+		engine, _ := NewAlertEngine([]string{"mounts.used_percent > 80/90"}, 5)
+		stats := SystemStats{Time: at(0), Mounts: []MountInfo{
+			{Path: "/", UsedPercent: 50},
+			{Path: "/data", UsedPercent: 85},
+			{Path: "/backup", UsedPercent: 99},
+		}}
+		stats.setStatus("mounts", nil)
+
+		// Act
+		stats = evaluate(engine, stats)
+
+		// Assert - most severe first
+		if len(stats.Alerts) != 2 || stats.Alerts[0].Name() != "mounts.used_percent{/backup}" || stats.Alerts[1].Instance != "/data" {
+			t.Fatalf("Expected alerts for /backup then /data, got %+v", stats.Alerts)
+		}
+
+		stats = SystemStats{Time: at(1), Mounts: []MountInfo{{Path: "/", UsedPercent: 50}, {Path: "/data", UsedPercent: 85}}}
+		stats.setStatus("mounts", nil)
+		stats = evaluate(engine, stats)
+		if len(stats.Alerts) != 1 || len(stats.AlertEvents) != 1 || stats.AlertEvents[0].Instance != "/backup" {
+			t.Errorf("Expected /backup to resolve once unmounted, got %+v / %+v", stats.Alerts, stats.AlertEvents)
+		}
+	})
+}
@@ -3,106 +3,418 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
 	"time"
 
 	ui "github.com/gizak/termui/v3"
-	"github.com/gizak/termui/v3/widgets"
 )
 
 // App encapsulates the application state and provides a clean interface for the monitor.
 // This struct groups related components and makes the code more organized and testable.
 type App struct {
-	cpuGauge    *widgets.Gauge
-	memoryGauge *widgets.Gauge
-	diskGauge   *widgets.Gauge
-	infoList    *widgets.List
-	ticker      *time.Ticker
-	uiEvents    <-chan ui.Event
-	monitor     SystemMonitor // App manages its own monitor instance
+	dash     *dashboard // All widgets on screen, nil in headless mode
+	ticker   *time.Ticker
+	uiEvents <-chan ui.Event  // Nil in headless mode, so it never fires
+	monitor  SystemMonitor    // App manages its own monitor instance
+	sampler  *Sampler         // Collects in the background so input never waits
+	out      io.Writer        // Where headless mode writes snapshots
+	metrics  *http.Server     // Prometheus endpoint, nil unless --listen is set
+	recorder *Recorder        // Writes the session file, nil unless --record is set
+	csv      *CSVLogger       // Appends a row per snapshot, nil unless --csv is set
+	notifier *WebhookNotifier // Posts alert notifications, nil unless --webhooks is set
+	hooks    *HookRunner      // Runs commands on alert changes, nil unless --hook is set
+	replay   *ReplayMonitor   // Plays back --replay in place of the machine, nil when monitoring live
+	paused   bool             // Sampling is paused with p; ticks are ignored until it resumes
+
+	// ctx is cancelled on SIGINT/SIGTERM; cancel stops every collection
+	// still in flight when the app exits
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 // newApp creates a new App instance with all components initialized and configured.
 // It now handles its own UI initialization and creates its own monitor for complete encapsulation.
+// With --output=jsonl the terminal UI is never initialized.
 func newApp() (*App, error) {
-	// Initialize the terminal UI system first
+	// Everything the app starts is tied to this context, which also ends on
+	// SIGINT/SIGTERM so headless runs under cron or SSH shut down cleanly
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	// Create the monitor instance - App handles its own dependencies.
+	// A replay stands in for the machine, so everything downstream is unchanged.
+	var monitor SystemMonitor
+	var replay *ReplayMonitor
+	if config.Replay != "" {
+		frames, err := LoadRecording(config.Replay)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		replay = NewReplayMonitor(frames, config.ReplaySpeed)
+		monitor = replay
+
+		// Show what was recorded, not what this machine would collect
+		config.Collectors = replay.Collectors()
+		if path := replay.DiskPath(); path != "" {
+			config.DiskDrive = path
+		}
+	} else {
+		monitor = NewGopsutilMonitor(Providers{})
+	}
+
+	app := &App{
+		ticker:  time.NewTicker(config.RefreshInterval), // Create ticker for periodic updates
+		monitor: monitor,                                // App owns its monitor
+		sampler: NewSampler(ctx, monitor),
+		replay:  replay,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+
+	// ALERTS - evaluated in the sampler so every output sees the same alerts
+	if len(config.Alerts) > 0 {
+		engine, err := NewAlertEngine(config.Alerts, config.AlertHysteresis)
+		if err != nil {
+			app.cleanup()
+			return nil, err
+		}
+		app.sampler.SetAlertEngine(engine)
+	}
+
+	// WEBHOOKS - alert events are posted in the background, never holding up
+	// sampling. Not for a replay, which would page people for old incidents.
+	if len(config.Webhooks) > 0 && replay == nil {
+		app.notifier = NewWebhookNotifier(config.Webhooks)
+		app.sampler.AddSink(app.notifier)
+	}
+
+	// HOOKS - local commands for alert changes, also run in the background.
+	// Never for a replay: its alerts are history, not this machine now.
+	if len(config.Hooks) > 0 && replay == nil {
+		// The dashboard discards the standard log, and with it every hook's output
+		if config.Output != outputJSONL && config.HookLog == "" {
+			app.cleanup()
+			return nil, fmt.Errorf("hooks need --hook-log with the dashboard, which hides their output")
+		}
+		hooks, err := NewHookRunner(config.Hooks, config.HookLog)
+		if err != nil {
+			app.cleanup()
+			return nil, err
+		}
+		app.sampler.AddSink(hooks)
+		app.hooks = hooks
+	}
+
+	// RECORDING - every snapshot also goes to the session file
+	if config.Record != "" {
+		recorder, err := NewRecorder(config.Record)
+		if err != nil {
+			app.cleanup()
+			return nil, err
+		}
+		app.sampler.AddSink(recorder)
+		app.recorder = recorder
+	}
+
+	// CSV - a row per snapshot for spreadsheets, whichever output is showing
+	if config.CSV != "" {
+		logger, err := NewCSVLogger(config.CSV, CSVRotation{Daily: config.CSVRotateDaily, MaxSize: config.CSVMaxSize, Keep: config.CSVKeep})
+		if err != nil {
+			app.cleanup()
+			return nil, err
+		}
+		app.sampler.AddSink(logger)
+		app.csv = logger
+	}
+
+	// PROMETHEUS - serve every snapshot alongside the TUI or headless output.
+	// Started before the UI so a busy port is reported on a normal terminal.
+	if config.Listen != "" {
+		exporter := NewPrometheusExporter()
+		app.sampler.AddSink(exporter)
+		server, err := startMetricsServer(config.Listen, exporter)
+		if err != nil {
+			app.cleanup()
+			return nil, err
+		}
+		app.metrics = server
+	}
+
+	// HEADLESS MODE - snapshots go to stdout, no terminal UI at all
+	if config.Output == outputJSONL {
+		app.out = os.Stdout
+		return app, nil
+	}
+
+	// Initialize the terminal UI system
 	if err := ui.Init(); err != nil {
+		app.cleanup()
 		return nil, fmt.Errorf("failed to initialize termui: %w", err)
 	}
 
-	// Create the monitor instance - App handles its own dependencies
-	monitor := NewGopsutilMonitor(realCPUProvider{}, realMemProvider{}, realDiskProvider{})
-
 	// Create UI components using the factory function from ui.go
-	cpuGauge, memoryGauge, diskGauge, infoList := createWidgets()
+	app.dash = createWidgets()
 
 	// Setup UI layout - position and style all widgets
-	setupUI(cpuGauge, memoryGauge, diskGauge, infoList)
-
-	// Create ticker for periodic updates
-	ticker := time.NewTicker(config.RefreshInterval)
+	setupUI(app.dash)
 
 	// Get UI event channel
-	uiEvents := ui.PollEvents()
-
-	return &App{
-		cpuGauge:    cpuGauge,
-		memoryGauge: memoryGauge,
-		diskGauge:   diskGauge,
-		infoList:    infoList,
-		ticker:      ticker,
-		uiEvents:    uiEvents,
-		monitor:     monitor, // App owns its monitor
-	}, nil
+	app.uiEvents = ui.PollEvents()
+
+	// The screen belongs to termui now - stray log lines would scribble over it.
+	// Collection errors are shown in the UI instead.
+	log.SetOutput(io.Discard)
+
+	return app, nil
 }
 
 // cleanup properly releases resources when the application exits.
 // Now handles both ticker and UI cleanup for complete resource management.
 func (app *App) cleanup() {
+	// Abandon collections still in flight, e.g. a hung disk call
+	if app.cancel != nil {
+		app.cancel()
+	}
 	if app.ticker != nil {
 		app.ticker.Stop()
 	}
-	// Close the UI system
-	ui.Close()
+	if app.metrics != nil {
+		app.metrics.Close()
+	}
+	// Close the UI system and give the terminal back to the log
+	if app.dash != nil {
+		ui.Close()
+		log.SetOutput(os.Stderr)
+	}
+	// Give queued notifications a moment to go out, logging any that fail
+	if app.notifier != nil {
+		app.notifier.Close()
+	}
+	if app.hooks != nil {
+		if err := app.hooks.Close(); err != nil {
+			log.Print(err)
+		}
+	}
+	// Finish the files last, so a write error reaches the restored log
+	if app.recorder != nil {
+		if err := app.recorder.Close(); err != nil {
+			log.Print(err)
+		}
+	}
+	if app.csv != nil {
+		if err := app.csv.Close(); err != nil {
+			log.Print(err)
+		}
+	}
 }
 
 // run executes the main application loop with event handling.
+// Collection happens in the sampler, so this loop only ever waits on input,
+// timer ticks and finished snapshots - it never blocks on a collector.
 func (app *App) run() {
-	// Do initial update to show data immediately
-	app.updateDisplay()
+	// Draw the empty layout right away and start the first collection
+	if app.dash != nil {
+		renderDashboard(app.dash)
+	}
+	app.trigger()
 
 	// Main event loop - clean and focused
 	for {
 		select {
+		case <-app.ctx.Done():
+			return // SIGINT/SIGTERM
 		case e := <-app.uiEvents:
 			if app.handleUIEvent(e) {
 				return // Exit requested
 			}
 		case <-app.ticker.C:
-			app.updateDisplay()
+			// Paused, or a paused replay that would only repeat its snapshot into the history
+			if app.paused || (app.replay != nil && !app.replay.Status().Playing) {
+				continue
+			}
+			// Skipped automatically if the previous collection is still running
+			app.trigger()
+		case stats := <-app.sampler.Updates():
+			if err := app.updateDisplay(stats); err != nil {
+				log.Printf("stopping: %v", err)
+				return // Nobody is reading any more, e.g. the pipe was closed
+			}
+			// A headless replay ends with the recording; the dashboard stays open
+			if app.dash == nil && app.replay != nil && app.replay.Finished(stats) {
+				return
+			}
 		}
 	}
 }
 
+// trigger starts the next collection, first moving a replay on to the
+// snapshot due now.
+func (app *App) trigger() {
+	if app.replay != nil {
+		app.replay.Advance(time.Now())
+	}
+	app.sampler.Trigger()
+}
+
 // handleUIEvent processes user input events and returns true if the app should exit.
 func (app *App) handleUIEvent(e ui.Event) bool {
+	if app.replay != nil && app.handleReplayKey(e.ID) {
+		return false
+	}
+
 	switch e.ID {
 	case "q", "<C-c>":
 		return true // Signal to exit
 	case "<Resize>":
 		app.handleResize(e)
+	case "<Tab>", "<Right>", "l":
+		switchTab(app.dash, 1)
+		renderDashboard(app.dash)
+	case "<Left>", "h":
+		switchTab(app.dash, -1)
+		renderDashboard(app.dash)
+	case "<Down>", "j":
+		scrollActiveView(app.dash, 1)
+		renderDashboard(app.dash)
+	case "<Up>", "k":
+		scrollActiveView(app.dash, -1)
+		renderDashboard(app.dash)
+	case "s":
+		cycleProcessSort(app.dash)
+		renderDashboard(app.dash)
+	case "p":
+		app.togglePause()
+	case "+", "=": // = is + without Shift on most keyboards
+		app.changeInterval(1)
+	case "-":
+		app.changeInterval(-1)
+	case "r":
+		app.trigger() // The result is drawn when it arrives
+	case "?":
+		toggleHelp(app.dash)
+		renderDashboard(app.dash)
+	case "<PageDown>":
+		scrollActiveView(app.dash, config.PageScroll)
+		renderDashboard(app.dash)
+	case "<PageUp>":
+		scrollActiveView(app.dash, -config.PageScroll)
+		renderDashboard(app.dash)
 	}
 	return false // Continue running
 }
 
+// handleReplayKey applies the playback keys of a replay and reports whether
+// the key was one of them. The new position is shown right away.
+func (app *App) handleReplayKey(key string) bool {
+	switch key {
+	case "<Space>", "p":
+		app.replay.TogglePause()
+	case ">", ".":
+		app.replay.Faster()
+	case "<", ",":
+		app.replay.Slower()
+	case "f":
+		app.replay.Seek(config.ReplaySeek)
+	case "b":
+		app.replay.Seek(-config.ReplaySeek)
+	default:
+		return false
+	}
+	app.trigger()
+	return true
+}
+
+// togglePause stops or restarts sampling. The dashboard keeps showing the
+// last snapshot while paused; resuming collects a fresh one right away.
+func (app *App) togglePause() {
+	app.paused = !app.paused
+	app.dash.paused = app.paused
+	updateTitleBar(app.dash)
+	renderDashboard(app.dash)
+	if !app.paused {
+		app.trigger()
+	}
+}
+
+// refreshIntervals are the steps + and - move the refresh interval through.
+var refreshIntervals = []time.Duration{
+	250 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second, 30 * time.Second,
+	time.Minute,
+}
+
+// nextInterval returns the next step of refreshIntervals above current
+// (direction > 0) or below it, or current if there is none. The interval
+// must stay longer than the CPU sample and within the history window.
+func nextInterval(current time.Duration, direction int) time.Duration {
+	if direction > 0 {
+		for _, interval := range refreshIntervals {
+			if interval > current {
+				if interval <= config.HistoryWindow {
+					return interval
+				}
+				break
+			}
+		}
+		return current
+	}
+	for _, interval := range slices.Backward(refreshIntervals) {
+		if interval < current {
+			if interval > config.CPUSampleDuration {
+				return interval
+			}
+			break
+		}
+	}
+	return current
+}
+
+// changeInterval moves the refresh interval one step up or down and resets
+// the ticker, so the new interval applies from now on.
+func (app *App) changeInterval(direction int) {
+	interval := nextInterval(config.RefreshInterval, direction)
+	if interval == config.RefreshInterval {
+		return // Already at the limit
+	}
+	config.RefreshInterval = interval
+	app.ticker.Reset(interval)
+
+	// The histories hold more or fewer samples to cover the same window
+	resizeHistories(app.dash)
+	updateTitleBar(app.dash)
+	renderDashboard(app.dash)
+}
+
 // handleResize recalculates layout when the terminal window is resized.
 func (app *App) handleResize(e ui.Event) {
 	payload := e.Payload.(ui.Resize)
-	setupUIWithSize(app.cpuGauge, app.memoryGauge, app.diskGauge, app.infoList, payload.Width, payload.Height)
-	ui.Render(app.cpuGauge, app.memoryGauge, app.diskGauge, app.infoList)
+	setupUIWithSize(app.dash, payload.Width, payload.Height)
+	ui.Clear() // Views may have shrunk, leaving old cells behind
+	renderDashboard(app.dash)
 }
 
-// updateDisplay refreshes the UI with current system data.
-func (app *App) updateDisplay() {
-	updateDisplay(app.cpuGauge, app.memoryGauge, app.diskGauge, app.infoList, app.monitor)
+// updateDisplay refreshes the UI with a finished snapshot,
+// or writes it as a JSON line in headless mode.
+func (app *App) updateDisplay(stats SystemStats) error {
+	if app.dash == nil {
+		if err := writeJSONLine(app.out, stats); err != nil {
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+		return nil
+	}
+	if app.replay != nil {
+		status := app.replay.Status()
+		app.dash.replay = &status
+		updateTitleBar(app.dash)
+	}
+	updateDisplay(app.dash, stats)
+	return nil
 }
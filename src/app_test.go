@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer that is safe to read while the app writes to it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestAppHeadless(t *testing.T) {
+	// Arrange - a headless app like newApp builds for --output=jsonl
+	originalCollectors := config.Collectors
+	config.Collectors = []string{"cpu"}
+	defer func() { config.Collectors = originalCollectors }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	monitor := &MockSystemMonitor{CPUUsage: 33.0}
+	out := &syncBuffer{}
+	app := &App{
+		ticker:  time.NewTicker(time.Hour), // Only the initial snapshot matters here
+		monitor: monitor,
+		sampler: NewSampler(ctx, monitor),
+		out:     out,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	defer app.cleanup()
+
+	// Act - run until the first snapshot is written, then "send a signal"
+	done := make(chan struct{})
+	go func() {
+		app.run()
+		close(done)
+	}()
+
+	deadline := time.After(2 * time.Second)
+	for !strings.Contains(out.String(), "\n") {
+		select {
+		case <-deadline:
+			t.Fatal("Timeout waiting for the first JSON line")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	cancel()
+
+	// Assert - the app exits and the line holds the snapshot
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("run did not return after cancellation")
+	}
+
+	var stats SystemStats
+	firstLine, _, _ := strings.Cut(out.String(), "\n")
+	if err := json.Unmarshal([]byte(firstLine), &stats); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if stats.CPUUsage != 33.0 {
+		t.Errorf("Expected cpu_usage 33.0, got %f", stats.CPUUsage)
+	}
+	if stats.Time.IsZero() {
+		t.Error("Expected a timestamp on the snapshot")
+	}
+}
+
+func TestNewAppReplay(t *testing.T) {
+	original := config
+	defer func() { config = original }()
+
+	// Arrange - a production config with actions on every alert
+	config.Replay = record(t, "session.jsonl", recordedFrames())
+	config.Output = outputJSONL
+	config.Alerts = []string{"cpu > 5"}
+	config.Hooks = map[string]string{hookAnyMetric: "/usr/local/bin/rotate-logs"}
+	config.Webhooks = []string{"https://hooks.example.com/alert"}
+
+	// Act
+	app, err := newApp()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer app.cleanup()
+
+	// Assert - replayed alerts must not run commands or page anyone
+	if app.hooks != nil {
+		t.Error("Expected no hook runner for a replay")
+	}
+	if app.notifier != nil {
+		t.Error("Expected no webhook notifier for a replay")
+	}
+}
+
+func TestNewAppHooksNeedLog(t *testing.T) {
+	original := config
+	defer func() { config = original }()
+
+	// Arrange - the dashboard, which would swallow the hook output
+	config.Output = outputTUI
+	config.Hooks = map[string]string{hookAnyMetric: "/usr/local/bin/rotate-logs"}
+	config.HookLog = ""
+
+	// Act
+	app, err := newApp()
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "--hook-log") {
+		if app != nil {
+			app.cleanup()
+		}
+		t.Errorf("Expected an error asking for --hook-log, got %v", err)
+	}
+}
+
+func TestNextInterval(t *testing.T) {
+	originalSample, originalWindow := config.CPUSampleDuration, config.HistoryWindow
+	defer func() { config.CPUSampleDuration, config.HistoryWindow = originalSample, originalWindow }()
+	config.CPUSampleDuration = 100 * time.Millisecond
+	config.HistoryWindow = 30 * time.Second
+
+	tests := []struct {
+		name      string
+		current   time.Duration
+		direction int
+		want      time.Duration
+	}{
+		{name: "Longer", current: time.Second, direction: 1, want: 2 * time.Second},
+		{name: "Shorter", current: time.Second, direction: -1, want: 500 * time.Millisecond},
+		{name: "BetweenSteps", current: 1500 * time.Millisecond, direction: 1, want: 2 * time.Second},
+		{name: "Fastest", current: 250 * time.Millisecond, direction: -1, want: 250 * time.Millisecond},
+		{name: "HistoryWindowLimit", current: 30 * time.Second, direction: 1, want: 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextInterval(tt.current, tt.direction); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("CPUSampleLimit", func(t *testing.T) {
+		config.CPUSampleDuration = 500 * time.Millisecond // Sampling must fit in the interval
+		if got := nextInterval(time.Second, -1); got != time.Second {
+			t.Errorf("Expected to stay at 1s, got %s", got)
+		}
+	})
+}
+
+func TestTitleText(t *testing.T) {
+	originalInterval := config.RefreshInterval
+	defer func() { config.RefreshInterval = originalInterval }()
+	config.RefreshInterval = 2 * time.Second
+
+	t.Run("Paused", func(t *testing.T) {
+		title := titleText(&dashboard{paused: true})
+		for _, want := range []string{"PAUSED", "refresh every 2s", "? for help"} {
+			if !strings.Contains(title, want) {
+				t.Errorf("Expected %q in the title, got %q", want, title)
+			}
+		}
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		d := &dashboard{paused: true, replay: &ReplayStatus{Elapsed: time.Second, Length: time.Minute, Speed: 2}}
+		title := titleText(d)
+		if !strings.Contains(title, "(1s of 1m0s), 2x, paused") || strings.Contains(title, "PAUSED") {
+			t.Errorf("Expected the replay status instead of PAUSED, got %q", title)
+		}
+	})
+}
+
+func TestHelpLines(t *testing.T) {
+	live, replay := helpLines(false), helpLines(true)
+	if len(live) != len(keyBindings) || len(replay) != len(live)+len(replayKeyBindings) {
+		t.Errorf("Expected the replay keys added to the help, got %d and %d lines", len(live), len(replay))
+	}
+}
@@ -0,0 +1,311 @@
+// Package main provides the check subcommand for the hardware monitor.
+// This file contains a one-shot check in the Nagios plugin format, so the
+// same binary can be run directly by Nagios, Icinga, Naemon or Sensu:
+//
+//	hw-monitor check -w cpu=80,disk=85 -c cpu=95,disk=95
+//	HWMON CRITICAL - disk.used_percent{/} 96.2% (critical) | cpu.usage_percent=12.5%;80;95 ...
+//
+// Thresholds are Nagios ranges, so "low is bad" metrics work too:
+// -w memory.available_percent=10: warns once less than 10% is available.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// checkState is a plugin state; its value is the exit code plugins must use.
+type checkState int
+
+const (
+	checkOK checkState = iota
+	checkWarning
+	checkCritical
+	checkUnknown // The value couldn't be determined, or the check was called wrong
+)
+
+// String returns the state as it appears on the status line.
+func (s checkState) String() string {
+	switch s {
+	case checkOK:
+		return "OK"
+	case checkWarning:
+		return "WARNING"
+	case checkCritical:
+		return "CRITICAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// worse reports whether s outranks other as the overall result. A metric that
+// couldn't be checked hides a warning but not a critical, as in Icinga.
+func (s checkState) worse(other checkState) bool {
+	rank := map[checkState]int{checkOK: 0, checkWarning: 1, checkUnknown: 2, checkCritical: 3}
+	return rank[s] > rank[other]
+}
+
+// checkDefaults are checked against the --gauge-thresholds when neither -w
+// nor -c is given.
+var checkDefaults = []string{"cpu", "memory", "disk"}
+
+// checkRange is a threshold in the Nagios range format:
+//
+//	10      alert outside 0..10, i.e. below 0 or above 10
+//	10:     alert below 10
+//	~:10    alert above 10
+//	10:20   alert outside 10..20
+//	@10:20  alert inside 10..20
+type checkRange struct {
+	text       string // As given, for the perfdata
+	start, end float64
+	inside     bool // Alert inside the range instead of outside it
+}
+
+// parseCheckRange parses a threshold in the Nagios range format.
+func parseCheckRange(text string) (checkRange, error) {
+	r := checkRange{text: text, end: math.Inf(1)}
+	spec, inside := strings.CutPrefix(text, "@")
+	r.inside = inside
+
+	start, end, hasColon := strings.Cut(spec, ":")
+	if !hasColon {
+		start, end = "0", spec
+	}
+	var err error
+	switch start {
+	case "~":
+		r.start = math.Inf(-1)
+	case "":
+		r.start = 0
+	default:
+		if r.start, err = strconv.ParseFloat(start, 64); err != nil {
+			return checkRange{}, fmt.Errorf("invalid range %q", text)
+		}
+	}
+	if end != "" {
+		if r.end, err = strconv.ParseFloat(end, 64); err != nil {
+			return checkRange{}, fmt.Errorf("invalid range %q", text)
+		}
+	}
+	if r.start > r.end {
+		return checkRange{}, fmt.Errorf("invalid range %q: start is above end", text)
+	}
+	return r, nil
+}
+
+// alerts reports whether value triggers the threshold.
+func (r checkRange) alerts(value float64) bool {
+	outside := value < r.start || value > r.end
+	return outside != r.inside
+}
+
+// metricCheck holds the thresholds for one metric; either may be missing.
+type metricCheck struct {
+	metric            string // Full metric name, as in alert rules
+	warning, critical *checkRange
+}
+
+// parseChecks combines -w and -c, both lists of metric=range, into one check
+// per metric, sorted by metric name.
+func parseChecks(warning, critical string) ([]metricCheck, error) {
+	checks := make(map[string]*metricCheck)
+	for _, arg := range []struct {
+		flag  string
+		value string
+	}{{"w", warning}, {"c", critical}} {
+		for _, item := range splitList(arg.value) {
+			name, text, ok := strings.Cut(item, "=")
+			if !ok {
+				return nil, fmt.Errorf("-%s: %q is not metric=range", arg.flag, item)
+			}
+			metric, known := canonicalMetric(strings.ToLower(strings.TrimSpace(name)))
+			if !known {
+				return nil, fmt.Errorf("-%s: unknown metric %q (known: %s)", arg.flag, name, strings.Join(sortedKeys(alertMetrics), ", "))
+			}
+			r, err := parseCheckRange(strings.TrimSpace(text))
+			if err != nil {
+				return nil, fmt.Errorf("-%s: %s: %w", arg.flag, name, err)
+			}
+
+			if checks[metric] == nil {
+				checks[metric] = &metricCheck{metric: metric}
+			}
+			if arg.flag == "w" {
+				checks[metric].warning = &r
+			} else {
+				checks[metric].critical = &r
+			}
+		}
+	}
+
+	result := make([]metricCheck, 0, len(checks))
+	for _, name := range sortedKeys(checks) {
+		result = append(result, *checks[name])
+	}
+	return result, nil
+}
+
+// defaultChecks checks the main gauges against their configured thresholds.
+func defaultChecks() []metricCheck {
+	var checks []metricCheck
+	for _, name := range checkDefaults {
+		t := config.GaugeThresholds[name]
+		warning, _ := parseCheckRange(formatThreshold(t.Warning))
+		critical, _ := parseCheckRange(formatThreshold(t.Critical))
+		metric, _ := canonicalMetric(name)
+		checks = append(checks, metricCheck{metric: metric, warning: &warning, critical: &critical})
+	}
+	return checks
+}
+
+// runCheck implements "hw-monitor check": it parses the thresholds and every
+// configuration flag, collects once, prints the status line and returns the
+// exit code.
+func runCheck(args []string, stdout, stderr io.Writer, lookupEnv func(string) (string, bool)) int {
+	fs := flag.NewFlagSet("hw-monitor check", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	warning := fs.String("w", "", "warning ranges by metric, e.g. cpu=80,memory.available_percent=10:")
+	critical := fs.String("c", "", "critical ranges by metric, e.g. cpu=95,disk=95")
+
+	cfg, err := parseConfig(fs, args, lookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return int(checkUnknown) // Usage was already printed
+	}
+	if err != nil {
+		fmt.Fprintf(stdout, "HWMON UNKNOWN - %v\n", err)
+		return int(checkUnknown)
+	}
+	config = cfg
+
+	checks, err := parseChecks(*warning, *critical)
+	if err != nil {
+		fmt.Fprintf(stdout, "HWMON UNKNOWN - %v\n", err)
+		return int(checkUnknown)
+	}
+	if len(checks) == 0 {
+		checks = defaultChecks()
+	}
+
+	monitor := NewGopsutilMonitor(Providers{})
+	return int(check(monitor, checks, stdout))
+}
+
+// check runs only the collectors the checks need, prints the status line
+// with perfdata and returns the overall state.
+func check(monitor SystemMonitor, checks []metricCheck, out io.Writer) checkState {
+	// COLLECT - just what the checked metrics come from, one interval
+	// apart if a rate is checked
+	config.Collectors = nil
+	for _, c := range checks {
+		if name := alertMetrics[c.metric].collector; !slices.Contains(config.Collectors, name) {
+			config.Collectors = append(config.Collectors, name)
+		}
+	}
+	stats := fetchSettledStats(context.Background(), monitor)
+
+	// COMPARE - the worst state wins, problems are listed most severe first
+	type finding struct {
+		state checkState
+		text  string
+	}
+	overall := checkOK
+	var findings, summary []finding
+	var perfdata []string
+	for _, c := range checks {
+		metric := alertMetrics[c.metric]
+		if !stats.Succeeded(metric.collector) {
+			status, _ := stats.StatusOf(metric.collector)
+			findings = append(findings, finding{checkUnknown, fmt.Sprintf("%s: %s", c.metric, status.Error)})
+			continue
+		}
+		samples := metric.samples(stats)
+		if len(samples) == 0 {
+			findings = append(findings, finding{checkUnknown, c.metric + ": no value"})
+			continue
+		}
+
+		for _, sample := range samples {
+			name := Alert{Metric: c.metric, Instance: sample.instance}.Name()
+			state := checkOK
+			switch {
+			case c.critical != nil && c.critical.alerts(sample.value):
+				state = checkCritical
+			case c.warning != nil && c.warning.alerts(sample.value):
+				state = checkWarning
+			}
+
+			value := strconv.FormatFloat(sample.value, 'f', config.DecimalPlaces, 64) + checkUnit(c.metric)
+			if state == checkOK {
+				summary = append(summary, finding{state, name + " " + value})
+			} else {
+				findings = append(findings, finding{state, fmt.Sprintf("%s %s (%s)", name, value, strings.ToLower(state.String()))})
+			}
+			perfdata = append(perfdata, perfDatum(name, c, sample.value))
+		}
+	}
+	for _, f := range findings {
+		if f.state.worse(overall) {
+			overall = f.state
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].state.worse(findings[j].state) })
+
+	// STATUS LINE - problems if there are any, otherwise every value
+	if len(findings) == 0 {
+		findings = summary
+	}
+	texts := make([]string, 0, len(findings))
+	for _, f := range findings {
+		texts = append(texts, f.text)
+	}
+	line := fmt.Sprintf("HWMON %s - %s", overall, strings.Join(texts, ", "))
+	if len(perfdata) > 0 {
+		line += " | " + strings.Join(perfdata, " ")
+	}
+	fmt.Fprintln(out, line)
+	return overall
+}
+
+// checkUnit returns the perfdata unit of a metric, judged by its name.
+func checkUnit(metric string) string {
+	switch {
+	case strings.HasSuffix(metric, "_percent"):
+		return "%"
+	case strings.HasSuffix(metric, "_ms"):
+		return "ms"
+	default:
+		return ""
+	}
+}
+
+// perfLabelPattern matches labels that can go into perfdata without quotes.
+var perfLabelPattern = regexp.MustCompile(`^[A-Za-z0-9_.]+$`)
+
+// perfDatum formats one value as 'label'=value[unit];warn;crit. Min and max
+// are left out: plugins may skip them for percentages, and load percentages
+// can go past 100 anyway.
+func perfDatum(label string, c metricCheck, value float64) string {
+	if !perfLabelPattern.MatchString(label) {
+		label = "'" + strings.ReplaceAll(label, "'", "''") + "'"
+	}
+	unit := checkUnit(c.metric)
+	rounded := math.Round(value*100) / 100 // Perfdata doesn't need float noise
+	fields := []string{label + "=" + strconv.FormatFloat(rounded, 'f', -1, 64) + unit, "", ""}
+	if c.warning != nil {
+		fields[1] = c.warning.text
+	}
+	if c.critical != nil {
+		fields[2] = c.critical.text
+	}
+	return strings.TrimRight(strings.Join(fields, ";"), ";")
+}
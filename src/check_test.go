@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/net"
+)
+
+func TestParseCheckRange(t *testing.T) {
+	tests := []struct {
+		text    string
+		alerts  []float64 // Values that must trigger the threshold
+		passes  []float64 // Values that must not
+		wantErr bool
+	}{
+		{text: "80", alerts: []float64{80.1, -1}, passes: []float64{0, 80}},
+		{text: "10:", alerts: []float64{9.9}, passes: []float64{10, 1000}},
+		{text: "~:10", alerts: []float64{10.1}, passes: []float64{-50, 10}},
+		{text: "10:20", alerts: []float64{9, 21}, passes: []float64{10, 15, 20}},
+		{text: "@10:20", alerts: []float64{10, 15, 20}, passes: []float64{9, 21}},
+		{text: "ninety", wantErr: true},
+		{text: "20:10", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			r, err := parseCheckRange(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			for _, v := range tt.alerts {
+				if !r.alerts(v) {
+					t.Errorf("Expected %g to trigger %q", v, tt.text)
+				}
+			}
+			for _, v := range tt.passes {
+				if r.alerts(v) {
+					t.Errorf("Expected %g not to trigger %q", v, tt.text)
+				}
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	original := config
+	defer func() { config = original }()
+
+	monitor := func() *MockSystemMonitor {
+		return &MockSystemMonitor{
+			CPUUsage:   50,
+			MemoryInfo: &MemoryInfo{UsedPercent: 85, Total: 100, Available: 15},
+			DiskInfo:   &DiskInfo{UsedPercent: 40},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		warning   string
+		critical  string
+		monitor   func(m *MockSystemMonitor)
+		wantState checkState
+		wantLine  string
+	}{
+		{
+			name: "OK", warning: "cpu=80", critical: "cpu=95",
+			wantState: checkOK, wantLine: "HWMON OK - cpu.usage_percent 50.0% | cpu.usage_percent=50%;80;95",
+		},
+		{
+			name: "Warning", warning: "cpu=80,memory=80", critical: "memory=95",
+			wantState: checkWarning, wantLine: "HWMON WARNING - memory.used_percent 85.0% (warning) | cpu.usage_percent=50%;80 memory.used_percent=85%;80;95",
+		},
+		{
+			name: "CriticalFirst", warning: "memory=80", critical: "cpu=40",
+			wantState: checkCritical, wantLine: "HWMON CRITICAL - cpu.usage_percent 50.0% (critical), memory.used_percent 85.0% (warning) |",
+		},
+		{
+			name: "LowIsBad", warning: "memory.available_percent=20:", critical: "memory.available_percent=10:",
+			wantState: checkWarning, wantLine: "memory.available_percent 15.0% (warning)",
+		},
+		{
+			name: "Unknown", warning: "cpu=80,memory=80",
+			monitor:   func(m *MockSystemMonitor) { m.CPUError = errors.New("no cpu") },
+			wantState: checkUnknown, wantLine: "HWMON UNKNOWN - cpu.usage_percent: no cpu, memory.used_percent 85.0% (warning) |",
+		},
+		{
+			name: "CriticalBeatsUnknown", critical: "cpu=80,disk=30",
+			monitor:   func(m *MockSystemMonitor) { m.CPUError = errors.New("no cpu") },
+			wantState: checkCritical, wantLine: "HWMON CRITICAL - disk.used_percent 40.0% (critical), cpu.usage_percent: no cpu |",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			config = original
+			m := monitor()
+			if tt.monitor != nil {
+				tt.monitor(m)
+			}
+			checks, err := parseChecks(tt.warning, tt.critical)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			// Act
+			var out bytes.Buffer
+			state := check(m, checks, &out)
+
+			// Assert
+			if state != tt.wantState {
+				t.Errorf("Expected %s, got %s", tt.wantState, state)
+			}
+			if !strings.Contains(out.String(), tt.wantLine) {
+				t.Errorf("Expected %q in the output, got %q", tt.wantLine, out.String())
+			}
+		})
+	}
+}
+
+// growingNetProvider reports another MiB received on eth0 with every call.
+type growingNetProvider struct {
+	calls *int
+}
+
+func (p growingNetProvider) IOCountersWithContext(ctx context.Context, pernic bool) ([]net.IOCountersStat, error) {
+	*p.calls++
+	return []net.IOCountersStat{{Name: "eth0", BytesRecv: uint64(*p.calls) << 20}}, nil
+}
+
+func TestCheckRates(t *testing.T) {
+	original := config
+	defer func() { config = original }()
+	config.RefreshInterval = 20 * time.Millisecond
+
+	// Arrange - a rate is only known from the second sample on
+	var calls int
+	monitor := NewGopsutilMonitor(Providers{Net: growingNetProvider{calls: &calls}})
+	checks, _ := parseChecks("", "network.rx_bytes_per_second=1000")
+
+	// Act
+	var out bytes.Buffer
+	state := check(monitor, checks, &out)
+
+	// Assert - a baseline first, then a real rate of about 50 MiB/s
+	if calls != 2 {
+		t.Errorf("Expected a baseline and a sample, got %d collections", calls)
+	}
+	if state != checkCritical || !strings.Contains(out.String(), "network.rx_bytes_per_second{eth0}") {
+		t.Errorf("Expected the receive rate to be critical, got %s: %q", state, out.String())
+	}
+}
+
+func TestRunCheckUsage(t *testing.T) {
+	original := config
+	defer func() { config = original }()
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "UnknownMetric", args: []string{"-w", "gpu=80"}, want: `unknown metric "gpu"`},
+		{name: "NotPair", args: []string{"-c", "95"}, want: "is not metric=range"},
+		{name: "BadConfig", args: []string{"--interval", "0s"}, want: "interval must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := runCheck(tt.args, &stdout, &stderr, fakeEnv(nil))
+
+			if code != int(checkUnknown) {
+				t.Errorf("Expected exit code 3, got %d", code)
+			}
+			if !strings.HasPrefix(stdout.String(), "HWMON UNKNOWN - ") || !strings.Contains(stdout.String(), tt.want) {
+				t.Errorf("Expected an UNKNOWN line with %q, got %q", tt.want, stdout.String())
+			}
+		})
+	}
+}
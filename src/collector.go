@@ -0,0 +1,343 @@
+// Package main provides the pluggable collector registry for the hardware monitor.
+// This file contains the Collector interface, the registry, and the built-in collectors.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+)
+
+// Collector gathers one kind of metric through a SystemMonitor.
+// Each collector owns both halves of its metric: fetching the data and
+// routing the result into SystemStats, so fetchSystemStats never needs to
+// know which metrics exist.
+type Collector interface {
+	// Name returns the unique registry name, also used as MetricResult.Type
+	Name() string
+
+	// Collect fetches the metric and wraps it in a MetricResult.
+	// It should give up as soon as ctx is done.
+	Collect(ctx context.Context, monitor SystemMonitor) MetricResult
+
+	// Apply copies a successful result into the stats snapshot
+	Apply(result MetricResult, stats *SystemStats)
+}
+
+// CollectorRegistry keeps collectors by name, remembering registration order
+// so that listings and displays stay stable between runs.
+type CollectorRegistry struct {
+	mu         sync.RWMutex
+	collectors map[string]Collector
+	order      []string
+}
+
+// NewCollectorRegistry creates an empty registry.
+func NewCollectorRegistry() *CollectorRegistry {
+	return &CollectorRegistry{collectors: make(map[string]Collector)}
+}
+
+// Register adds a collector to the registry.
+// Names must be unique - registering the same name twice is an error.
+func (r *CollectorRegistry) Register(c Collector) error {
+	name := c.Name()
+	if name == "" {
+		return fmt.Errorf("collector name must not be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.collectors[name]; exists {
+		return fmt.Errorf("collector %q is already registered", name)
+	}
+	r.collectors[name] = c
+	r.order = append(r.order, name)
+	return nil
+}
+
+// Lookup returns the collector registered under name.
+func (r *CollectorRegistry) Lookup(name string) (Collector, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.collectors[name]
+	return c, ok
+}
+
+// Names returns all registered collector names in registration order.
+func (r *CollectorRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, len(r.order))
+	copy(names, r.order)
+	return names
+}
+
+// Enabled resolves a list of collector names into collectors.
+// Unknown names are reported as an error so typos in configuration don't
+// silently disable a metric.
+func (r *CollectorRegistry) Enabled(names []string) ([]Collector, error) {
+	enabled := make([]Collector, 0, len(names))
+	for _, name := range names {
+		c, ok := r.Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown collector %q (available: %v)", name, r.Names())
+		}
+		enabled = append(enabled, c)
+	}
+	return enabled, nil
+}
+
+// collectors is the default registry used by fetchSystemStats.
+// The built-in collectors are registered here; new ones use RegisterCollector.
+var collectors = newDefaultRegistry()
+
+// newDefaultRegistry builds a registry holding all built-in collectors.
+func newDefaultRegistry() *CollectorRegistry {
+	registry := NewCollectorRegistry()
+	builtins := []Collector{
+		cpuCollector{},
+		coresCollector{},
+		memoryCollector{},
+		&diskCollector{},
+		mountsCollector{},
+		diskIOCollector{},
+		processesCollector{},
+		networkCollector{},
+		hostCollector{},
+	}
+	for _, c := range builtins {
+		if err := registry.Register(c); err != nil {
+			panic(err) // Built-in names are fixed, so this is a programming error
+		}
+	}
+	return registry
+}
+
+// RegisterCollector adds a collector to the default registry.
+// Once registered it can be enabled by name through config.Collectors.
+func RegisterCollector(c Collector) error {
+	return collectors.Register(c)
+}
+
+// cpuCollector reports overall CPU usage.
+type cpuCollector struct{}
+
+func (cpuCollector) Name() string { return "cpu" }
+
+func (c cpuCollector) Collect(ctx context.Context, monitor SystemMonitor) MetricResult {
+	// The sample duration is how long gopsutil measures CPU activity
+	cpuUsage, err := contextMonitor(monitor).GetCPUUsageContext(ctx, config.CPUSampleDuration)
+	if err != nil {
+		return MetricResult{Type: c.Name(), Value: nil, Error: err}
+	}
+	return MetricResult{Type: c.Name(), Value: cpuUsage, Error: nil}
+}
+
+func (cpuCollector) Apply(result MetricResult, stats *SystemStats) {
+	if cpuUsage, ok := result.Value.(float64); ok {
+		stats.CPUUsage = cpuUsage
+	}
+}
+
+// coresCollector reports usage for every logical CPU, so a single pinned core
+// isn't hidden by the aggregate.
+type coresCollector struct{}
+
+func (coresCollector) Name() string { return "cores" }
+
+func (c coresCollector) Collect(ctx context.Context, monitor SystemMonitor) MetricResult {
+	coreUsage, err := contextMonitor(monitor).GetPerCoreUsageContext(ctx, config.CPUSampleDuration)
+	if err != nil {
+		return MetricResult{Type: c.Name(), Value: nil, Error: err}
+	}
+	return MetricResult{Type: c.Name(), Value: coreUsage, Error: nil}
+}
+
+func (coresCollector) Apply(result MetricResult, stats *SystemStats) {
+	if coreUsage, ok := result.Value.([]float64); ok {
+		stats.CoreUsage = coreUsage
+	}
+}
+
+// memoryCollector reports virtual memory and swap usage.
+type memoryCollector struct{}
+
+func (memoryCollector) Name() string { return "memory" }
+
+func (c memoryCollector) Collect(ctx context.Context, monitor SystemMonitor) MetricResult {
+	memoryInfo, err := contextMonitor(monitor).GetMemoryUsageContext(ctx)
+	if err != nil {
+		return MetricResult{Type: c.Name(), Value: nil, Error: err}
+	}
+	return MetricResult{Type: c.Name(), Value: memoryInfo, Error: nil}
+}
+
+func (memoryCollector) Apply(result MetricResult, stats *SystemStats) {
+	if memInfo, ok := result.Value.(*MemoryInfo); ok {
+		stats.MemoryUsage = memInfo.UsedPercent
+		// Convert bytes to gigabytes using config constant
+		stats.MemoryUsed = float64(memInfo.Used) / float64(config.BytesToGB)
+		stats.MemoryTotal = float64(memInfo.Total) / float64(config.BytesToGB)
+		stats.SwapUsage = memInfo.SwapUsedPercent
+		stats.SwapUsed = float64(memInfo.SwapUsed) / float64(config.BytesToGB)
+		stats.SwapTotal = float64(memInfo.SwapTotal) / float64(config.BytesToGB)
+		stats.MemoryDetail = memInfo
+	}
+}
+
+// diskCollector reports space usage for config.DiskDrive.
+// A path that turns out not to exist is remembered and not queried again:
+// a vanished mount won't come back by hammering it every tick.
+type diskCollector struct {
+	mu      sync.Mutex
+	missing map[string]error // Paths found missing, with the error reported for them
+}
+
+func (*diskCollector) Name() string { return "disk" }
+
+func (c *diskCollector) Collect(ctx context.Context, monitor SystemMonitor) MetricResult {
+	path := config.DiskDrive
+
+	// Don't retry a path we already know is gone
+	if err := c.missingError(path); err != nil {
+		return MetricResult{Type: c.Name(), Value: nil, Error: err}
+	}
+
+	diskInfo, err := contextMonitor(monitor).GetDiskUsageContext(ctx, path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = fmt.Errorf("disk path %q does not exist, not retrying: %w", path, err)
+			c.markMissing(path, err)
+		}
+		return MetricResult{Type: c.Name(), Value: nil, Error: err}
+	}
+	return MetricResult{Type: c.Name(), Value: diskInfo, Error: nil}
+}
+
+func (*diskCollector) Apply(result MetricResult, stats *SystemStats) {
+	if diskInfo, ok := result.Value.(*DiskInfo); ok {
+		stats.DiskUsage = diskInfo.UsedPercent
+		// Convert bytes to gigabytes using config constant
+		stats.DiskUsed = float64(diskInfo.Used) / float64(config.BytesToGB)
+		stats.DiskTotal = float64(diskInfo.Total) / float64(config.BytesToGB)
+		stats.DiskPath = config.DiskDrive
+	}
+}
+
+// missingError returns the remembered error for a missing path, or nil.
+func (c *diskCollector) missingError(path string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.missing[path]
+}
+
+// markMissing remembers that path does not exist.
+func (c *diskCollector) markMissing(path string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.missing == nil {
+		c.missing = make(map[string]error)
+	}
+	c.missing[path] = err
+}
+
+// mountsCollector reports space usage for every mounted filesystem
+// that passes the configured include/exclude rules.
+type mountsCollector struct{}
+
+func (mountsCollector) Name() string { return "mounts" }
+
+func (c mountsCollector) Collect(ctx context.Context, monitor SystemMonitor) MetricResult {
+	mounts, err := contextMonitor(monitor).GetMountUsageContext(ctx, config.mountFilter())
+	if err != nil {
+		return MetricResult{Type: c.Name(), Value: nil, Error: err}
+	}
+	return MetricResult{Type: c.Name(), Value: mounts, Error: nil}
+}
+
+func (mountsCollector) Apply(result MetricResult, stats *SystemStats) {
+	if mounts, ok := result.Value.([]MountInfo); ok {
+		stats.Mounts = mounts
+	}
+}
+
+// processesCollector reports the busiest processes by CPU, memory and I/O.
+type processesCollector struct{}
+
+func (processesCollector) Name() string { return "processes" }
+
+func (c processesCollector) Collect(ctx context.Context, monitor SystemMonitor) MetricResult {
+	procs, err := contextMonitor(monitor).GetProcessesContext(ctx, config.TopProcesses)
+	if err != nil {
+		return MetricResult{Type: c.Name(), Value: nil, Error: err}
+	}
+	return MetricResult{Type: c.Name(), Value: procs, Error: nil}
+}
+
+func (processesCollector) Apply(result MetricResult, stats *SystemStats) {
+	if procs, ok := result.Value.([]ProcessInfo); ok {
+		stats.Processes = procs
+	}
+}
+
+// diskIOCollector reports per-device disk throughput, IOPS, utilization and latency.
+type diskIOCollector struct{}
+
+func (diskIOCollector) Name() string { return "diskio" }
+
+func (c diskIOCollector) Collect(ctx context.Context, monitor SystemMonitor) MetricResult {
+	devices, err := contextMonitor(monitor).GetDiskIOContext(ctx, config.DiskIODevices)
+	if err != nil {
+		return MetricResult{Type: c.Name(), Value: nil, Error: err}
+	}
+	return MetricResult{Type: c.Name(), Value: devices, Error: nil}
+}
+
+func (diskIOCollector) Apply(result MetricResult, stats *SystemStats) {
+	if devices, ok := result.Value.([]DiskIOInfo); ok {
+		stats.DiskIO = devices
+	}
+}
+
+// networkCollector reports per-interface network throughput.
+type networkCollector struct{}
+
+func (networkCollector) Name() string { return "network" }
+
+func (c networkCollector) Collect(ctx context.Context, monitor SystemMonitor) MetricResult {
+	interfaces, err := contextMonitor(monitor).GetNetworkUsageContext(ctx)
+	if err != nil {
+		return MetricResult{Type: c.Name(), Value: nil, Error: err}
+	}
+	return MetricResult{Type: c.Name(), Value: interfaces, Error: nil}
+}
+
+func (networkCollector) Apply(result MetricResult, stats *SystemStats) {
+	if interfaces, ok := result.Value.([]NetInterfaceInfo); ok {
+		stats.Network = interfaces
+	}
+}
+
+// hostCollector reports load averages, uptime and the identity of the machine.
+type hostCollector struct{}
+
+func (hostCollector) Name() string { return "host" }
+
+func (c hostCollector) Collect(ctx context.Context, monitor SystemMonitor) MetricResult {
+	info, err := contextMonitor(monitor).GetHostInfoContext(ctx)
+	if err != nil {
+		return MetricResult{Type: c.Name(), Value: nil, Error: err}
+	}
+	return MetricResult{Type: c.Name(), Value: info, Error: nil}
+}
+
+func (hostCollector) Apply(result MetricResult, stats *SystemStats) {
+	if info, ok := result.Value.(*HostInfo); ok {
+		stats.Host = info
+	}
+}
@@ -1,12 +1,17 @@
 package main
 
-import "time"
+import (
+	"os"
+	"runtime"
+	"time"
+)
 
-// Config holds all configuration constants for the hardware monitor.
+// AppConfig holds all configuration for the hardware monitor.
 // This includes both user-configurable settings and application constants.
-var Config = struct {
+type AppConfig struct {
 	// Display settings
 	RefreshInterval time.Duration
+	HistoryWindow   time.Duration // How far back the sparklines reach
 	TimeFormat      string
 	Title           string
 	Separator       string
@@ -14,40 +19,236 @@ var Config = struct {
 	// Precision
 	DecimalPlaces int
 
+	// Output mode: "tui" for the dashboard, "jsonl" for headless JSON Lines on stdout
+	Output string
+
+	// Address for the Prometheus /metrics endpoint, e.g. ":9101" (empty = disabled)
+	Listen string
+
 	// System settings
-	DiskDrive         string
+	DiskDrive         string // Empty means auto-detect, see defaultDiskPath
 	CPUSampleDuration time.Duration
 
+	// Collectors lists the registered collectors to run, by name
+	Collectors []string
+
+	// How long a collector may run before the fetcher stops waiting for it
+	CollectorTimeout  time.Duration            // Default for every collector
+	CollectorTimeouts map[string]time.Duration // Per-collector overrides, by name
+
+	// Processes - used by the "processes" collector
+	TopProcesses int // Keep this many processes by each of CPU, memory and I/O
+
+	// Disk I/O - used by the "diskio" collector
+	DiskIODevices []string // Only report these block devices (empty = all)
+
+	// Network - used by the "network" collector
+	NetExcludeInterfaces []string // Never report these interfaces
+
+	// Alerting - see alerts.go for the rule syntax
+	Alerts          []string // Rules such as "cpu > 80/95 for 30s"
+	AlertHysteresis float64  // Percent of the threshold a value must move back before an alert clears
+
+	// Gauge colors - green, then yellow from Warning, then red from Critical
+	GaugeThresholds map[string]GaugeThreshold // By gauge name, see gaugeNames
+	CriticalStyle   string                    // How a critical gauge stands out: "bold", "blink" or "none"
+
+	// Webhooks - see webhook.go
+	Webhooks          []string      // POST alert notifications to these URLs (empty = off)
+	WebhookTimeout    time.Duration // Per request; also how long shutdown waits for queued notifications
+	WebhookRetries    int           // Further attempts after a failed delivery
+	WebhookQueue      int           // Notifications kept per URL while it is unreachable
+	WebhookBackoff    time.Duration // Wait before the first retry, doubled for each one after
+	WebhookMaxBackoff time.Duration // Longest wait between retries
+
+	// Exec hooks - see hooks.go
+	Hooks       map[string]string // Command to run by metric (or "*" for all) when its alerts change
+	HookTimeout time.Duration     // How long a hook may run before it is killed
+	HookLog     string            // File that receives hook output (empty = the standard log, headless only)
+	HookQueue   int               // Runs waiting behind a slow hook before the oldest are dropped
+
+	// Recording and replay - see record.go and replay.go
+	Record      string        // Write every snapshot to this session file (empty = off)
+	Replay      string        // Play this session file back instead of monitoring the machine
+	ReplaySpeed float64       // Initial playback speed, 1 for real time
+	ReplaySeek  time.Duration // How far the seek keys jump
+
+	// CSV logging - see csvlog.go
+	CSV            string // Append one row per snapshot to this file (empty = off)
+	CSVRotateDaily bool   // Start a new file every day
+	CSVMaxSize     int64  // Start a new file before this many bytes (0 = no limit)
+	CSVKeep        int    // Rotated files kept (0 = all)
+
+	// Host - used by the "host" collector
+	HostInfoRefresh time.Duration // How often the hostname, kernel, CPU model etc. are re-read
+
+	// Mounted filesystems - used by the "mounts" collector
+	AllDisks            bool     // Enable the mounts collector on top of Collectors
+	MountIncludeFSTypes []string // Only report these filesystem types (empty = all)
+	MountExcludeFSTypes []string // Never report these filesystem types
+	MountExcludePaths   []string // Never report mount points under these paths
+
 	// Universal constants - these don't change across configurations
-	BytesToGB     int64 // Convert bytes to gigabytes (1024³)
-	ScreenThirds  int   // Divide screen into thirds for layout
-	ScreenHalves  int   // Divide screen into halves for layout
-	MetricCount   int   // Number of metrics we collect (CPU, Memory, Disk)
-	ChannelBuffer int   // Buffer size for stats channel
-	ResultsBuffer int   // Buffer size for results channel
-}{
+	BytesToGB      int64 // Convert bytes to gigabytes (1024³)
+	ScreenQuarters int   // Divide screen into quarters for the gauges
+	ScreenHalves   int   // Divide screen into halves for layout
+	TitleBarHeight int   // Rows taken by the title bar, which has no border
+	TabBarHeight   int   // Rows taken by the tab bar, including its border
+	BannerHeight   int   // Rows taken by the alert banner, including its border
+	PageScroll     int   // Rows moved by PageUp/PageDown in scrollable views
+	MaxBarWidth    int   // Widest a bar chart bar may get, so few cores don't make huge blocks
+	ChannelBuffer  int   // Buffer size for stats channel
+}
+
+// Config holds the compiled-in defaults.
+// Flags, environment variables and the config file are layered on top of
+// these values by loadConfig - see configload.go.
+var Config = AppConfig{
 	// Refresh the display every second
 	RefreshInterval: 1 * time.Second,
 
+	// Sparklines cover the last 5 minutes
+	HistoryWindow: 5 * time.Minute,
+
 	// Time format (24-hour format HH:MM:SS)
 	TimeFormat: "15:04:05",
 
+	// Interactive dashboard unless asked for headless output
+	Output: outputTUI,
+
 	// Display text
-	Title:     "Hardware Monitor - Press Ctrl+C to stop",
+	Title:     "Hardware Monitor",
 	Separator: "=========================================",
 
 	// Number formatting
 	DecimalPlaces: 1,
 
 	// System monitoring settings
-	DiskDrive:         "C:",
+	DiskDrive:         "", // Auto-detected at startup
 	CPUSampleDuration: 100 * time.Millisecond,
 
+	// Collectors enabled by default - see collector.go for the registry
+	Collectors: []string{"cpu", "cores", "memory", "disk", "diskio", "processes", "network", "host"},
+
+	// Warn early, go critical when action is needed; brief spikes are normal
+	Alerts: []string{
+		"cpu > 85/95 for 1m",
+		"memory > 85/95 for 30s",
+		"disk > 85/95",
+	},
+	AlertHysteresis: 5,
+
+	// Gauges turn yellow a little before the alerts warn; swap in use at all deserves attention
+	GaugeThresholds: map[string]GaugeThreshold{
+		"cpu":    {Warning: 70, Critical: 90},
+		"memory": {Warning: 80, Critical: 95},
+		"swap":   {Warning: 50, Critical: 80},
+		"disk":   {Warning: 85, Critical: 95},
+	},
+	CriticalStyle: criticalBold,
+
+	// Ride out a webhook receiver restarting; beyond that, newer alerts matter more
+	WebhookTimeout:    5 * time.Second,
+	WebhookRetries:    5,
+	WebhookQueue:      100,
+	WebhookBackoff:    1 * time.Second,
+	WebhookMaxBackoff: 1 * time.Minute,
+
+	// Room for a cleanup script to do real work, but not to hang forever
+	HookTimeout: 30 * time.Second,
+	HookQueue:   100,
+
+	// Replays start in real time; seeking half a minute skips a few refreshes
+	ReplaySpeed: 1,
+	ReplaySeek:  30 * time.Second,
+
+	// A week of daily files; rotation itself is off until asked for
+	CSVKeep: 7,
+
+	// Hostnames and kernels rarely change while we run - no need to re-read them every tick
+	HostInfoRefresh: 10 * time.Minute,
+
+	// Loopback traffic never leaves the machine
+	NetExcludeInterfaces: []string{"lo", "lo0"},
+
+	// Enough to fill a tall terminal for each sort column
+	TopProcesses: 30,
+
+	// Generous enough for a CPU sample, short enough to notice a hung mount
+	CollectorTimeout: 2 * time.Second,
+
+	// Pseudo and virtual filesystems that don't represent real storage
+	MountExcludeFSTypes: []string{
+		"tmpfs", "devtmpfs", "overlay", "proc", "sysfs", "cgroup", "cgroup2",
+		"devpts", "squashfs", "securityfs", "pstore", "debugfs", "tracefs",
+		"mqueue", "hugetlbfs", "fusectl", "configfs", "autofs", "bpf",
+		"binfmt_misc", "nsfs", "rpc_pipefs", "ramfs", "efivarfs", "selinuxfs",
+	},
+	MountExcludePaths: []string{"/proc", "/sys", "/dev"},
+
 	// Universal constants - initialized once
-	BytesToGB:     1024 * 1024 * 1024, // 1024³
-	ScreenThirds:  3,
-	ScreenHalves:  2,
-	MetricCount:   3,
-	ChannelBuffer: 1,
-	ResultsBuffer: 3,
+	BytesToGB:      1024 * 1024 * 1024, // 1024³
+	ScreenQuarters: 4,
+	ScreenHalves:   2,
+	TitleBarHeight: 1,
+	TabBarHeight:   3,
+	BannerHeight:   3,
+	PageScroll:     10,
+	MaxBarWidth:    8,
+	ChannelBuffer:  1,
+}
+
+// gaugeNames lists the gauges GaugeThresholds can configure, in screen order.
+var gaugeNames = []string{"cpu", "memory", "swap", "disk"}
+
+// GaugeThreshold holds the percentages at which a gauge turns yellow and red.
+type GaugeThreshold struct {
+	Warning  float64
+	Critical float64
+}
+
+// level returns how serious a gauge reading is, reusing the alert levels.
+func (t GaugeThreshold) level(value float64) AlertLevel {
+	switch {
+	case value >= t.Critical:
+		return LevelCritical
+	case value >= t.Warning:
+		return LevelWarning
+	default:
+		return LevelNone
+	}
+}
+
+// collectorTimeout returns the timeout for the named collector.
+func (c AppConfig) collectorTimeout(name string) time.Duration {
+	if timeout, ok := c.CollectorTimeouts[name]; ok {
+		return timeout
+	}
+	return c.CollectorTimeout
+}
+
+// historySize returns how many samples cover HistoryWindow at RefreshInterval.
+func (c AppConfig) historySize() int {
+	return max(int(c.HistoryWindow/c.RefreshInterval), 1)
+}
+
+// mountFilter builds the filter used by the mounts collector.
+func (c AppConfig) mountFilter() MountFilter {
+	return MountFilter{
+		IncludeFSTypes: c.MountIncludeFSTypes,
+		ExcludeFSTypes: c.MountExcludeFSTypes,
+		ExcludePaths:   c.MountExcludePaths,
+	}
+}
+
+// defaultDiskPath returns the root filesystem for the current platform.
+// It is used when no disk is configured: the system drive on Windows, "/" elsewhere.
+func defaultDiskPath() string {
+	if runtime.GOOS == "windows" {
+		if drive := os.Getenv("SystemDrive"); drive != "" {
+			return drive
+		}
+		return "C:"
+	}
+	return "/"
 }
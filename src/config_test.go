@@ -0,0 +1,344 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeEnv returns a lookupEnv function backed by a map.
+func fakeEnv(values map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+}
+
+// writeConfigFile writes content to a temporary config file and returns its path.
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hw-monitor.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	// Act
+	cfg, err := loadConfig(nil, fakeEnv(nil))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.RefreshInterval != Config.RefreshInterval {
+		t.Errorf("Expected default interval %s, got %s", Config.RefreshInterval, cfg.RefreshInterval)
+	}
+	if cfg.DecimalPlaces != Config.DecimalPlaces {
+		t.Errorf("Expected default precision %d, got %d", Config.DecimalPlaces, cfg.DecimalPlaces)
+	}
+	if cfg.DiskDrive != defaultDiskPath() {
+		t.Errorf("Expected auto-detected disk %q, got %q", defaultDiskPath(), cfg.DiskDrive)
+	}
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	// Arrange - every source sets the interval, each lower source sets one extra value
+	diskPath := t.TempDir()
+	path := writeConfigFile(t, fmt.Sprintf(`{
+		"interval": "5s",
+		"precision": 3,
+		"cpu_sample": "200ms",
+		"disk": %q
+	}`, diskPath))
+	env := fakeEnv(map[string]string{
+		"HWMON_CONFIG":    path,
+		"HWMON_INTERVAL":  "4s",
+		"HWMON_PRECISION": "2",
+	})
+	args := []string{"--interval", "3s"}
+
+	// Act
+	cfg, err := loadConfig(args, env)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.RefreshInterval != 3*time.Second {
+		t.Errorf("Flag should win: expected interval 3s, got %s", cfg.RefreshInterval)
+	}
+	if cfg.DecimalPlaces != 2 {
+		t.Errorf("Env should beat file: expected precision 2, got %d", cfg.DecimalPlaces)
+	}
+	if cfg.CPUSampleDuration != 200*time.Millisecond {
+		t.Errorf("File should beat defaults: expected cpu-sample 200ms, got %s", cfg.CPUSampleDuration)
+	}
+	if cfg.DiskDrive != diskPath {
+		t.Errorf("Expected disk from file, got %q", cfg.DiskDrive)
+	}
+}
+
+func TestLoadConfigEmptyEnv(t *testing.T) {
+	// Arrange - the file sets alert rules, the environment clears them
+	path := writeConfigFile(t, `{"alerts": ["cpu > 90"], "precision": 3}`)
+	env := fakeEnv(map[string]string{"HWMON_CONFIG": path, "HWMON_ALERTS": ""})
+
+	// Act
+	cfg, err := loadConfig(nil, env)
+
+	// Assert - set but empty counts, unset leaves the lower source alone
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(cfg.Alerts) != 0 {
+		t.Errorf("Expected HWMON_ALERTS= to turn alerting off, got %q", cfg.Alerts)
+	}
+	if cfg.DecimalPlaces != 3 {
+		t.Errorf("Expected precision 3 from the file, got %d", cfg.DecimalPlaces)
+	}
+}
+
+func TestLoadConfigCollectors(t *testing.T) {
+	t.Run("Flag", func(t *testing.T) {
+		cfg, err := loadConfig([]string{"--collectors", "cpu, memory"}, fakeEnv(nil))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if strings.Join(cfg.Collectors, ",") != "cpu,memory" {
+			t.Errorf("Expected collectors cpu,memory, got %v", cfg.Collectors)
+		}
+	})
+
+	t.Run("AllDisks", func(t *testing.T) {
+		cfg, err := loadConfig([]string{"--all-disks"}, fakeEnv(nil))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !containsFold(cfg.Collectors, "mounts") {
+			t.Errorf("Expected --all-disks to enable the mounts collector, got %v", cfg.Collectors)
+		}
+	})
+
+	t.Run("FileList", func(t *testing.T) {
+		path := writeConfigFile(t, `{"collectors": ["disk"]}`)
+		cfg, err := loadConfig([]string{"--config", path}, fakeEnv(nil))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if strings.Join(cfg.Collectors, ",") != "disk" {
+			t.Errorf("Expected collectors disk, got %v", cfg.Collectors)
+		}
+	})
+}
+
+func TestLoadConfigTimeouts(t *testing.T) {
+	t.Run("Flags", func(t *testing.T) {
+		cfg, err := loadConfig([]string{"--timeout", "3s", "--timeouts", "disk=5s, mounts=10s"}, fakeEnv(nil))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := cfg.collectorTimeout("disk"); got != 5*time.Second {
+			t.Errorf("Expected disk timeout 5s, got %s", got)
+		}
+		if got := cfg.collectorTimeout("cpu"); got != 3*time.Second {
+			t.Errorf("Expected cpu to fall back to --timeout 3s, got %s", got)
+		}
+	})
+
+	t.Run("FileObject", func(t *testing.T) {
+		path := writeConfigFile(t, `{"timeouts": {"mounts": "10s"}}`)
+		cfg, err := loadConfig([]string{"--config", path}, fakeEnv(nil))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := cfg.collectorTimeout("mounts"); got != 10*time.Second {
+			t.Errorf("Expected mounts timeout 10s, got %s", got)
+		}
+	})
+}
+
+func TestLoadConfigHooks(t *testing.T) {
+	// Arrange - commands with commas from every source; the flags drop one
+	// hook from the file and override another
+	path := writeConfigFile(t, `{"hook": {"disk": "curl -d '{\"a\":1,\"b\":2}' http://ops", "cpu": "cpu-alert", "memory": "free -m"}}`)
+	env := fakeEnv(map[string]string{"HWMON_HOOK": "swap=awk -F, '{print $1}' /proc/swaps\nload=uptime"})
+	args := []string{"--config", path, "--hook", "memory=", "--hook", "cpu=logger -t hwmon cpu,high", "--hook", "*=notify-send alert"}
+
+	// Act
+	cfg, err := loadConfig(args, env)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := map[string]string{
+		"disk": `curl -d '{"a":1,"b":2}' http://ops`,
+		"cpu":  "logger -t hwmon cpu,high",
+		"swap": "awk -F, '{print $1}' /proc/swaps",
+		"load": "uptime",
+		"*":    "notify-send alert",
+	}
+	if fmt.Sprint(cfg.Hooks) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, cfg.Hooks)
+	}
+	if len(Config.Hooks) != 0 {
+		t.Errorf("Expected the defaults to stay untouched, got %v", Config.Hooks)
+	}
+}
+
+func TestLoadConfigCSVRotate(t *testing.T) {
+	tests := []struct {
+		value     string
+		wantDaily bool
+		wantSize  int64
+	}{
+		{value: "daily", wantDaily: true},
+		{value: "100MB", wantSize: 100 << 20},
+		{value: "Daily, 1.5 GiB", wantDaily: true, wantSize: 3 << 29},
+		{value: "4096", wantSize: 4096},
+		{value: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			cfg, err := loadConfig([]string{"--csv-rotate", tt.value}, fakeEnv(nil))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.CSVRotateDaily != tt.wantDaily || cfg.CSVMaxSize != tt.wantSize {
+				t.Errorf("Expected daily %v and size %d, got %v and %d", tt.wantDaily, tt.wantSize, cfg.CSVRotateDaily, cfg.CSVMaxSize)
+			}
+		})
+	}
+}
+
+func TestLoadConfigGaugeThresholds(t *testing.T) {
+	t.Run("Flags", func(t *testing.T) {
+		// Act - override one gauge, the others keep their defaults
+		cfg, err := loadConfig([]string{"--gauge-thresholds", "disk = 60/75.5", "--critical-style", "blink"}, fakeEnv(nil))
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := cfg.GaugeThresholds["disk"]; got != (GaugeThreshold{Warning: 60, Critical: 75.5}) {
+			t.Errorf("Expected disk thresholds 60/75.5, got %+v", got)
+		}
+		if got := cfg.GaugeThresholds["cpu"]; got != Config.GaugeThresholds["cpu"] {
+			t.Errorf("Expected default cpu thresholds, got %+v", got)
+		}
+		if Config.GaugeThresholds["disk"].Warning == 60 {
+			t.Error("Expected the defaults to be left unchanged")
+		}
+		if cfg.CriticalStyle != criticalBlink {
+			t.Errorf("Expected critical style blink, got %q", cfg.CriticalStyle)
+		}
+	})
+
+	t.Run("FileObject", func(t *testing.T) {
+		path := writeConfigFile(t, `{"gauge_thresholds": {"swap": "10/20"}}`)
+		cfg, err := loadConfig([]string{"--config", path}, fakeEnv(nil))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := cfg.GaugeThresholds["swap"]; got != (GaugeThreshold{Warning: 10, Critical: 20}) {
+			t.Errorf("Expected swap thresholds 10/20, got %+v", got)
+		}
+	})
+}
+
+func TestGaugeThresholdLevel(t *testing.T) {
+	threshold := GaugeThreshold{Warning: 70, Critical: 90}
+	tests := []struct {
+		value float64
+		want  AlertLevel
+	}{
+		{value: 5, want: LevelNone},
+		{value: 69.9, want: LevelNone},
+		{value: 70, want: LevelWarning},
+		{value: 90, want: LevelCritical},
+		{value: 100, want: LevelCritical},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.value), func(t *testing.T) {
+			if got := threshold.level(tt.value); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		file    string
+		wantErr string
+	}{
+		{name: "BadDuration", args: []string{"--interval", "fast"}, wantErr: "--interval"},
+		{name: "NegativeInterval", args: []string{"--interval", "-1s"}, wantErr: "interval must be positive"},
+		{name: "SampleTooLong", args: []string{"--cpu-sample", "2s"}, wantErr: "must be shorter than interval"},
+		{name: "BadPrecision", args: []string{"--precision", "12"}, wantErr: "precision must be between"},
+		{name: "BadEnv", env: map[string]string{"HWMON_PRECISION": "lots"}, wantErr: "HWMON_PRECISION"},
+		{name: "UnknownCollector", args: []string{"--collectors", "cpu,gpu"}, wantErr: "unknown collector"},
+		{name: "DuplicateCollector", args: []string{"--collectors", "cpu,cpu"}, wantErr: "more than once"},
+		{name: "UnknownFileKey", file: `{"intervall": "1s"}`, wantErr: "unknown setting"},
+		{name: "BadFileValue", file: `{"precision": {"x": 1}}`, wantErr: "precision"},
+		{name: "MalformedFile", file: `{`, wantErr: "failed to parse config file"},
+		{name: "MissingDisk", args: []string{"--disk", "/this/path/does/not/exist"}, wantErr: "is not accessible"},
+		{name: "BadTimeout", args: []string{"--timeout", "0s"}, wantErr: "timeout must be positive"},
+		{name: "TimeoutUnknownCollector", args: []string{"--timeouts", "gpu=1s"}, wantErr: "unknown collector"},
+		{name: "TimeoutNotPair", args: []string{"--timeouts", "disk"}, wantErr: "name=duration"},
+		{name: "TimeoutShorterThanSample", args: []string{"--timeouts", "cpu=50ms"}, wantErr: "must be longer than cpu-sample"},
+		{name: "UnknownOutput", args: []string{"--output=xml"}, wantErr: "output must be"},
+		{name: "BadListen", args: []string{"--listen", "9101"}, wantErr: "listen address"},
+		{name: "HistoryTooShort", args: []string{"--history", "500ms"}, wantErr: "must be at least one interval"},
+		{name: "BadTopProcesses", args: []string{"--top-processes", "0"}, wantErr: "top-processes must be at least 1"},
+		{name: "BadAlertRule", args: []string{"--alerts", "cpu > 95/80"}, wantErr: "must come before critical"},
+		{name: "UnknownAlertMetric", args: []string{"--alerts", "gpu > 90"}, wantErr: "unknown metric"},
+		{name: "BadHysteresis", args: []string{"--alert-hysteresis", "100"}, wantErr: "alert-hysteresis must be between"},
+		{name: "UnknownGauge", args: []string{"--gauge-thresholds", "gpu=50/90"}, wantErr: "unknown gauge"},
+		{name: "GaugeNotPair", args: []string{"--gauge-thresholds", "cpu=90"}, wantErr: "not warning/critical"},
+		{name: "GaugeMisordered", args: []string{"--gauge-thresholds", "cpu=90/70"}, wantErr: "warning < critical"},
+		{name: "GaugeOverHundred", args: []string{"--gauge-thresholds", "cpu=90/120"}, wantErr: "critical <= 100"},
+		{name: "BadCriticalStyle", args: []string{"--critical-style", "flashy"}, wantErr: "critical-style must be"},
+		{name: "RecordAndReplay", args: []string{"--record", "a.jsonl", "--replay", "b.jsonl"}, wantErr: "can't be used together"},
+		{name: "BadReplaySpeed", args: []string{"--replay-speed", "0"}, wantErr: "replay-speed must be between"},
+		{name: "BadWebhookURL", args: []string{"--webhooks", "hooks.example.com/alert"}, wantErr: "not an http or https URL"},
+		{name: "BadWebhookQueue", args: []string{"--webhook-queue", "0"}, wantErr: "webhook-queue must be at least 1"},
+		{name: "UnknownHookMetric", args: []string{"--hook", "gpu=/bin/true"}, wantErr: "hook: unknown metric"},
+		{name: "HookNotPair", args: []string{"--hook", "/usr/local/bin/rotate-logs"}, wantErr: "not metric=command"},
+		{name: "HookFileNotObject", file: `{"hook": "disk=/usr/local/bin/rotate-logs"}`, wantErr: "must be an object"},
+		{name: "BadCSVRotate", args: []string{"--csv-rotate", "weekly"}, wantErr: "is not a size"},
+		{name: "BadCSVKeep", args: []string{"--csv-keep", "-1"}, wantErr: "csv-keep must not be negative"},
+		{name: "CSVIsRecording", args: []string{"--csv", "a.jsonl", "--record", "a.jsonl"}, wantErr: "same file"},
+		{name: "ExtraArgument", args: []string{"extra"}, wantErr: "unexpected argument"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"--config", writeConfigFile(t, tt.file)}, args...)
+			}
+
+			// Act
+			_, err := loadConfig(args, fakeEnv(tt.env))
+
+			// Assert
+			if err == nil {
+				t.Fatalf("Expected error containing %q, got nil", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
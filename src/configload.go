@@ -0,0 +1,806 @@
+// Package main provides configuration loading for the hardware monitor.
+// This file layers command-line flags, environment variables and an optional
+// JSON config file on top of the compiled-in defaults from config.go.
+//
+// Precedence, highest first: flags > environment > config file > defaults.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"math"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envPrefix is prepended to every option name to build its environment variable.
+// For example --cpu-sample becomes HWMON_CPU_SAMPLE.
+const envPrefix = "HWMON_"
+
+// configOption describes one user-configurable setting.
+// The same setter is used for every source, so a value is parsed identically
+// whether it comes from a flag, the environment, or the config file.
+type configOption struct {
+	name    string                                   // Flag name; env and file keys are derived from it
+	usage   string                                   // Help text shown by --help
+	boolean bool                                     // Flag may be given without a value
+	set     func(cfg *AppConfig, value string) error // Parses value into cfg
+
+	// fileSet decodes the config file value, for options whose file form is
+	// structured rather than a string; set is used when it is nil
+	fileSet func(cfg *AppConfig, raw json.RawMessage) error
+}
+
+// configOptions lists every setting that can be changed without recompiling.
+var configOptions = []configOption{
+	{
+		name:  "interval",
+		usage: "refresh interval, e.g. 500ms or 2s",
+		set: func(cfg *AppConfig, value string) error {
+			return setDuration(&cfg.RefreshInterval, value)
+		},
+	},
+	{
+		name:  "history",
+		usage: "how far back the history sparklines reach, e.g. 5m",
+		set: func(cfg *AppConfig, value string) error {
+			return setDuration(&cfg.HistoryWindow, value)
+		},
+	},
+	{
+		name:  "disk",
+		usage: "disk path or drive to monitor, e.g. / or C: (default: auto-detect)",
+		set: func(cfg *AppConfig, value string) error {
+			cfg.DiskDrive = value
+			return nil
+		},
+	},
+	{
+		name:  "cpu-sample",
+		usage: "how long each CPU measurement samples for, e.g. 100ms",
+		set: func(cfg *AppConfig, value string) error {
+			return setDuration(&cfg.CPUSampleDuration, value)
+		},
+	},
+	{
+		name:  "precision",
+		usage: "number of decimal places shown for values",
+		set: func(cfg *AppConfig, value string) error {
+			places, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("not an integer")
+			}
+			cfg.DecimalPlaces = places
+			return nil
+		},
+	},
+	{
+		name:  "time-format",
+		usage: "Go time layout for the clock, e.g. 15:04:05",
+		set: func(cfg *AppConfig, value string) error {
+			cfg.TimeFormat = value
+			return nil
+		},
+	},
+	{
+		name:  "output",
+		usage: "output mode: tui for the dashboard, jsonl for JSON Lines on stdout",
+		set: func(cfg *AppConfig, value string) error {
+			cfg.Output = value
+			return nil
+		},
+	},
+	{
+		name:  "listen",
+		usage: "serve Prometheus metrics on this address, e.g. :9101 (default: off)",
+		set: func(cfg *AppConfig, value string) error {
+			cfg.Listen = value
+			return nil
+		},
+	},
+	{
+		name:  "collectors",
+		usage: "comma-separated list of collectors to enable",
+		set: func(cfg *AppConfig, value string) error {
+			cfg.Collectors = splitList(value)
+			return nil
+		},
+	},
+	{
+		name:  "timeout",
+		usage: "how long any collector may run before it is reported as timed out",
+		set: func(cfg *AppConfig, value string) error {
+			return setDuration(&cfg.CollectorTimeout, value)
+		},
+	},
+	{
+		name:  "timeouts",
+		usage: "per-collector timeouts overriding --timeout, e.g. disk=5s,mounts=10s",
+		set: func(cfg *AppConfig, value string) error {
+			timeouts := make(map[string]time.Duration)
+			for _, item := range splitList(value) {
+				name, duration, ok := strings.Cut(item, "=")
+				if !ok {
+					return fmt.Errorf("%q is not name=duration", item)
+				}
+				var d time.Duration
+				if err := setDuration(&d, strings.TrimSpace(duration)); err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+				timeouts[strings.TrimSpace(name)] = d
+			}
+			cfg.CollectorTimeouts = timeouts
+			return nil
+		},
+	},
+	{
+		name:  "top-processes",
+		usage: "how many of the busiest processes to keep for each sort column",
+		set: func(cfg *AppConfig, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("not an integer")
+			}
+			cfg.TopProcesses = n
+			return nil
+		},
+	},
+	{
+		name:  "io-devices",
+		usage: "comma-separated block devices to report disk I/O for (default: all)",
+		set: func(cfg *AppConfig, value string) error {
+			cfg.DiskIODevices = splitList(value)
+			return nil
+		},
+	},
+	{
+		name:  "net-exclude",
+		usage: "comma-separated network interfaces to skip",
+		set: func(cfg *AppConfig, value string) error {
+			cfg.NetExcludeInterfaces = splitList(value)
+			return nil
+		},
+	},
+	{
+		name:  "webhooks",
+		usage: "comma-separated URLs to POST alert notifications to as JSON",
+		set: func(cfg *AppConfig, value string) error {
+			cfg.Webhooks = splitList(value)
+			return nil
+		},
+	},
+	{
+		name:  "webhook-timeout",
+		usage: "how long a webhook request may take, e.g. 5s",
+		set: func(cfg *AppConfig, value string) error {
+			return setDuration(&cfg.WebhookTimeout, value)
+		},
+	},
+	{
+		name:  "webhook-retries",
+		usage: "how often a failed webhook delivery is retried, with exponential backoff",
+		set: func(cfg *AppConfig, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("not an integer")
+			}
+			cfg.WebhookRetries = n
+			return nil
+		},
+	},
+	{
+		name:  "webhook-queue",
+		usage: "notifications kept per webhook while it is unreachable; the oldest are dropped beyond that",
+		set: func(cfg *AppConfig, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("not an integer")
+			}
+			cfg.WebhookQueue = n
+			return nil
+		},
+	},
+	{
+		// Repeatable, and never split on commas: commands are full of them
+		name:  "hook",
+		usage: "command to run when alerts on a metric fire or resolve, e.g. disk=/usr/local/bin/rotate-logs (* for every metric; repeat for more, one per line in the environment)",
+		set: func(cfg *AppConfig, value string) error {
+			for _, line := range strings.Split(value, "\n") {
+				if strings.TrimSpace(line) == "" {
+					continue
+				}
+				name, command, ok := strings.Cut(line, "=")
+				if !ok {
+					return fmt.Errorf("%q is not metric=command", line)
+				}
+				setHook(cfg, name, command)
+			}
+			return nil
+		},
+		fileSet: func(cfg *AppConfig, raw json.RawMessage) error {
+			var hooks map[string]string
+			if err := json.Unmarshal(raw, &hooks); err != nil {
+				return fmt.Errorf("must be an object of metric to command")
+			}
+			for name, command := range hooks {
+				setHook(cfg, name, command)
+			}
+			return nil
+		},
+	},
+	{
+		name:  "hook-timeout",
+		usage: "how long a hook may run before it is killed, e.g. 30s",
+		set: func(cfg *AppConfig, value string) error {
+			return setDuration(&cfg.HookTimeout, value)
+		},
+	},
+	{
+		name:  "hook-log",
+		usage: "append hook output to this file (default: stderr; required for hooks with the dashboard)",
+		set: func(cfg *AppConfig, value string) error {
+			cfg.HookLog = value
+			return nil
+		},
+	},
+	{
+		name:  "record",
+		usage: "write every snapshot to this session file for --replay (gzip-compressed if it ends in .gz)",
+		set: func(cfg *AppConfig, value string) error {
+			cfg.Record = value
+			return nil
+		},
+	},
+	{
+		name:  "csv",
+		usage: "append one CSV row per snapshot to this file, alongside the TUI or headless output",
+		set: func(cfg *AppConfig, value string) error {
+			cfg.CSV = value
+			return nil
+		},
+	},
+	{
+		name:  "csv-rotate",
+		usage: "start a new CSV file daily, at a size like 100MB, or both: daily,100MB (empty for never)",
+		set: func(cfg *AppConfig, value string) error {
+			cfg.CSVRotateDaily, cfg.CSVMaxSize = false, 0
+			for _, item := range splitList(value) {
+				if strings.EqualFold(item, "daily") {
+					cfg.CSVRotateDaily = true
+					continue
+				}
+				if err := setByteSize(&cfg.CSVMaxSize, item); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		name:  "csv-keep",
+		usage: "rotated CSV files to keep, oldest deleted first (0 keeps all)",
+		set: func(cfg *AppConfig, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("not an integer")
+			}
+			cfg.CSVKeep = n
+			return nil
+		},
+	},
+	{
+		name:  "replay",
+		usage: "play back a session file written by --record instead of monitoring this machine",
+		set: func(cfg *AppConfig, value string) error {
+			cfg.Replay = value
+			return nil
+		},
+	},
+	{
+		name:  "replay-speed",
+		usage: "initial playback speed for --replay, e.g. 0.5 or 10 (1 is real time)",
+		set: func(cfg *AppConfig, value string) error {
+			speed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("not a number")
+			}
+			cfg.ReplaySpeed = speed
+			return nil
+		},
+	},
+	{
+		name:  "alerts",
+		usage: "comma-separated alert rules, e.g. \"cpu > 80/95 for 30s, disk > 90\" (empty for none)",
+		set: func(cfg *AppConfig, value string) error {
+			cfg.Alerts = splitList(value)
+			return nil
+		},
+	},
+	{
+		name:  "gauge-thresholds",
+		usage: "per-gauge warning/critical percentages, e.g. cpu=70/90,disk=85/95 (others keep their defaults)",
+		set: func(cfg *AppConfig, value string) error {
+			thresholds := maps.Clone(cfg.GaugeThresholds)
+			if thresholds == nil {
+				thresholds = make(map[string]GaugeThreshold)
+			}
+			for _, item := range splitList(value) {
+				name, levels, ok := strings.Cut(item, "=")
+				if !ok {
+					return fmt.Errorf("%q is not name=warning/critical", item)
+				}
+				warning, critical, ok := strings.Cut(levels, "/")
+				if !ok {
+					return fmt.Errorf("%s: %q is not warning/critical", name, levels)
+				}
+				var t GaugeThreshold
+				var errW, errC error
+				t.Warning, errW = strconv.ParseFloat(strings.TrimSpace(warning), 64)
+				t.Critical, errC = strconv.ParseFloat(strings.TrimSpace(critical), 64)
+				if errW != nil || errC != nil {
+					return fmt.Errorf("%s: %q is not warning/critical", name, levels)
+				}
+				thresholds[strings.TrimSpace(name)] = t
+			}
+			cfg.GaugeThresholds = thresholds
+			return nil
+		},
+	},
+	{
+		name:  "critical-style",
+		usage: "how a gauge past its critical threshold stands out: bold, blink or none",
+		set: func(cfg *AppConfig, value string) error {
+			cfg.CriticalStyle = value
+			return nil
+		},
+	},
+	{
+		name:  "alert-hysteresis",
+		usage: "percent of the threshold a value must move back before an alert clears",
+		set: func(cfg *AppConfig, value string) error {
+			h, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("not a number")
+			}
+			cfg.AlertHysteresis = h
+			return nil
+		},
+	},
+	{
+		name:    "all-disks",
+		usage:   "report every mounted filesystem (enables the mounts collector)",
+		boolean: true,
+		set: func(cfg *AppConfig, value string) error {
+			return setBool(&cfg.AllDisks, value)
+		},
+	},
+	{
+		name:  "mount-include-fs",
+		usage: "comma-separated filesystem types to report with --all-disks (default: all)",
+		set: func(cfg *AppConfig, value string) error {
+			cfg.MountIncludeFSTypes = splitList(value)
+			return nil
+		},
+	},
+	{
+		name:  "mount-exclude-fs",
+		usage: "comma-separated filesystem types to skip with --all-disks",
+		set: func(cfg *AppConfig, value string) error {
+			cfg.MountExcludeFSTypes = splitList(value)
+			return nil
+		},
+	},
+	{
+		name:  "mount-exclude-paths",
+		usage: "comma-separated mount point prefixes to skip with --all-disks",
+		set: func(cfg *AppConfig, value string) error {
+			cfg.MountExcludePaths = splitList(value)
+			return nil
+		},
+	},
+}
+
+// configFlags holds the raw values of configuration flags after parsing.
+// Only flags the user actually passed are recorded, so they can be applied
+// last and override every other source.
+type configFlags struct {
+	configPath string
+	values     map[string][]string // Every value in order, for repeatable flags like --hook
+}
+
+// registerConfigFlags defines all configuration flags on fs.
+// Subcommands call this on their own FlagSet to share the same options.
+func registerConfigFlags(fs *flag.FlagSet) *configFlags {
+	cf := &configFlags{values: make(map[string][]string)}
+
+	fs.StringVar(&cf.configPath, "config", "", "path to a JSON config file (env "+envPrefix+"CONFIG)")
+	for _, opt := range configOptions {
+		name := opt.name // Capture for the closure
+		usage := fmt.Sprintf("%s (env %s)", opt.usage, envName(name))
+		record := func(value string) error {
+			cf.values[name] = append(cf.values[name], value)
+			return nil
+		}
+		if opt.boolean {
+			fs.BoolFunc(name, usage, record)
+		} else {
+			fs.Func(name, usage, record)
+		}
+	}
+	return cf
+}
+
+// resolve builds the final configuration from all sources and validates it.
+func (cf *configFlags) resolve(lookupEnv func(string) (string, bool)) (AppConfig, error) {
+	cfg := Config
+	// Copy slices so the defaults are never modified through cfg
+	cfg.Collectors = append([]string(nil), Config.Collectors...)
+	cfg.Alerts = append([]string(nil), Config.Alerts...)
+	cfg.CollectorTimeouts = nil                              // Setters replace the whole map
+	cfg.GaugeThresholds = maps.Clone(Config.GaugeThresholds) // Setters override single gauges
+	cfg.Hooks = maps.Clone(Config.Hooks)                     // Setters override single metrics
+
+	// LAYER 1: config file (flag wins over env for locating it)
+	path := cf.configPath
+	if path == "" {
+		path, _ = lookupEnv(envPrefix + "CONFIG")
+	}
+	if path != "" {
+		if err := applyConfigFile(&cfg, path); err != nil {
+			return cfg, err
+		}
+	}
+
+	// LAYER 2: environment variables - set ones count even when empty, so
+	// e.g. HWMON_ALERTS= turns alerting off
+	for _, opt := range configOptions {
+		value, ok := lookupEnv(envName(opt.name))
+		if !ok {
+			continue
+		}
+		if err := opt.set(&cfg, value); err != nil {
+			return cfg, fmt.Errorf("invalid %s=%q: %w", envName(opt.name), value, err)
+		}
+	}
+
+	// LAYER 3: command-line flags, applied in the order given
+	for _, opt := range configOptions {
+		for _, value := range cf.values[opt.name] {
+			if err := opt.set(&cfg, value); err != nil {
+				return cfg, fmt.Errorf("invalid value %q for --%s: %w", value, opt.name, err)
+			}
+		}
+	}
+
+	// Fill in anything left for auto-detection
+	if cfg.DiskDrive == "" {
+		cfg.DiskDrive = defaultDiskPath()
+	}
+	if cfg.AllDisks && !containsFold(cfg.Collectors, "mounts") {
+		cfg.Collectors = append(cfg.Collectors, "mounts")
+	}
+
+	if err := validateConfig(cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// loadConfig parses args and builds the configuration for the monitor.
+// lookupEnv (os.LookupEnv) is injected so tests don't depend on the real environment.
+func loadConfig(args []string, lookupEnv func(string) (string, bool)) (AppConfig, error) {
+	return parseConfig(flag.NewFlagSet("hw-monitor", flag.ContinueOnError), args, lookupEnv)
+}
+
+// parseConfig is loadConfig for a flag set that may define flags of its own,
+// such as the thresholds of the check subcommand. They are parsed along with
+// the configuration flags.
+func parseConfig(fs *flag.FlagSet, args []string, lookupEnv func(string) (string, bool)) (AppConfig, error) {
+	cf := registerConfigFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return Config, err
+	}
+	if fs.NArg() > 0 {
+		return Config, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	return cf.resolve(lookupEnv)
+}
+
+// applyConfigFile reads a JSON object of option names to values.
+// Keys use underscores, e.g. {"interval": "2s", "cpu_sample": "200ms"}.
+func applyConfigFile(cfg *AppConfig, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	// Apply in option order rather than map order so errors are deterministic
+	known := make(map[string]bool, len(configOptions))
+	for _, opt := range configOptions {
+		key := fileKey(opt.name)
+		known[key] = true
+
+		rawValue, ok := raw[key]
+		if !ok {
+			continue
+		}
+		if opt.fileSet != nil {
+			if err := opt.fileSet(cfg, rawValue); err != nil {
+				return fmt.Errorf("config file %s: invalid %q: %w", path, key, err)
+			}
+			continue
+		}
+		value, err := fileValueString(rawValue)
+		if err != nil {
+			return fmt.Errorf("config file %s: invalid %q: %w", path, key, err)
+		}
+		if err := opt.set(cfg, value); err != nil {
+			return fmt.Errorf("config file %s: invalid %q value %q: %w", path, key, value, err)
+		}
+	}
+
+	for key := range raw {
+		if !known[key] {
+			return fmt.Errorf("config file %s: unknown setting %q", path, key)
+		}
+	}
+	return nil
+}
+
+// fileValueString converts a JSON value into the string form the setters expect.
+// Strings, numbers and booleans are used as-is; string arrays become comma lists
+// and objects become sorted name=value lists, e.g. {"disk": "5s"} -> disk=5s.
+func fileValueString(raw json.RawMessage) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber() // Keep numbers exactly as written
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", err
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return "", fmt.Errorf("list items must be strings")
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, ","), nil
+	case map[string]interface{}:
+		items := make([]string, 0, len(v))
+		for name, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return "", fmt.Errorf("object values must be strings")
+			}
+			items = append(items, name+"="+s)
+		}
+		sort.Strings(items) // Map order is random; keep errors deterministic
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value type")
+	}
+}
+
+// validateConfig rejects settings that would make the monitor misbehave.
+func validateConfig(cfg AppConfig) error {
+	if cfg.RefreshInterval <= 0 {
+		return fmt.Errorf("interval must be positive, got %s", cfg.RefreshInterval)
+	}
+	if cfg.HistoryWindow < cfg.RefreshInterval {
+		return fmt.Errorf("history (%s) must be at least one interval (%s)", cfg.HistoryWindow, cfg.RefreshInterval)
+	}
+	if cfg.CPUSampleDuration <= 0 {
+		return fmt.Errorf("cpu-sample must be positive, got %s", cfg.CPUSampleDuration)
+	}
+	if cfg.CPUSampleDuration >= cfg.RefreshInterval {
+		return fmt.Errorf("cpu-sample (%s) must be shorter than interval (%s)", cfg.CPUSampleDuration, cfg.RefreshInterval)
+	}
+	if cfg.DecimalPlaces < 0 || cfg.DecimalPlaces > 6 {
+		return fmt.Errorf("precision must be between 0 and 6, got %d", cfg.DecimalPlaces)
+	}
+	if cfg.TimeFormat == "" {
+		return fmt.Errorf("time-format must not be empty")
+	}
+	if cfg.Output != outputTUI && cfg.Output != outputJSONL {
+		return fmt.Errorf("output must be %s or %s, got %q", outputTUI, outputJSONL, cfg.Output)
+	}
+	if cfg.TopProcesses < 1 {
+		return fmt.Errorf("top-processes must be at least 1, got %d", cfg.TopProcesses)
+	}
+	if cfg.Listen != "" {
+		if _, _, err := net.SplitHostPort(cfg.Listen); err != nil {
+			return fmt.Errorf("listen address %q is invalid (use host:port or :port): %w", cfg.Listen, err)
+		}
+	}
+	for name, t := range cfg.GaugeThresholds {
+		if !slices.Contains(gaugeNames, name) {
+			return fmt.Errorf("gauge-thresholds: unknown gauge %q (available: %v)", name, gaugeNames)
+		}
+		if t.Warning < 0 || t.Warning >= t.Critical || t.Critical > 100 {
+			return fmt.Errorf("gauge-thresholds: %s needs 0 <= warning < critical <= 100, got %g/%g", name, t.Warning, t.Critical)
+		}
+	}
+	if !slices.Contains([]string{criticalBold, criticalBlink, criticalNone}, cfg.CriticalStyle) {
+		return fmt.Errorf("critical-style must be %s, %s or %s, got %q", criticalBold, criticalBlink, criticalNone, cfg.CriticalStyle)
+	}
+	for _, webhook := range cfg.Webhooks {
+		if u, err := url.Parse(webhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhooks: %q is not an http or https URL", webhook)
+		}
+	}
+	if cfg.WebhookTimeout <= 0 {
+		return fmt.Errorf("webhook-timeout must be positive, got %s", cfg.WebhookTimeout)
+	}
+	if cfg.WebhookRetries < 0 {
+		return fmt.Errorf("webhook-retries must not be negative, got %d", cfg.WebhookRetries)
+	}
+	if cfg.WebhookQueue < 1 {
+		return fmt.Errorf("webhook-queue must be at least 1, got %d", cfg.WebhookQueue)
+	}
+	for name := range cfg.Hooks {
+		if _, err := hookMetric(name); err != nil {
+			return err
+		}
+	}
+	if cfg.HookTimeout <= 0 {
+		return fmt.Errorf("hook-timeout must be positive, got %s", cfg.HookTimeout)
+	}
+	if cfg.Record != "" && cfg.Replay != "" {
+		return fmt.Errorf("record and replay can't be used together")
+	}
+	if cfg.CSV != "" && cfg.CSV == cfg.Record {
+		return fmt.Errorf("csv and record can't write to the same file")
+	}
+	if cfg.CSVKeep < 0 {
+		return fmt.Errorf("csv-keep must not be negative, got %d", cfg.CSVKeep)
+	}
+	if cfg.ReplaySpeed < minReplaySpeed || cfg.ReplaySpeed > maxReplaySpeed {
+		return fmt.Errorf("replay-speed must be between %g and %g, got %g", minReplaySpeed, maxReplaySpeed, cfg.ReplaySpeed)
+	}
+	if cfg.AlertHysteresis < 0 || cfg.AlertHysteresis >= 100 {
+		return fmt.Errorf("alert-hysteresis must be between 0 and 100, got %g", cfg.AlertHysteresis)
+	}
+	if _, err := NewAlertEngine(cfg.Alerts, cfg.AlertHysteresis); err != nil {
+		return err
+	}
+	if len(cfg.Collectors) == 0 {
+		return fmt.Errorf("at least one collector must be enabled")
+	}
+	seen := make(map[string]bool, len(cfg.Collectors))
+	for _, name := range cfg.Collectors {
+		if seen[name] {
+			return fmt.Errorf("collector %q is listed more than once", name)
+		}
+		seen[name] = true
+	}
+	if _, err := collectors.Enabled(cfg.Collectors); err != nil {
+		return err
+	}
+	if cfg.CollectorTimeout <= 0 {
+		return fmt.Errorf("timeout must be positive, got %s", cfg.CollectorTimeout)
+	}
+	for name, timeout := range cfg.CollectorTimeouts {
+		if _, ok := collectors.Lookup(name); !ok {
+			return fmt.Errorf("timeouts: unknown collector %q (available: %v)", name, collectors.Names())
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("timeouts: %s must be positive, got %s", name, timeout)
+		}
+	}
+	// A CPU collector that can't finish its own sample would always time out
+	for _, name := range []string{"cpu", "cores"} {
+		if seen[name] && cfg.collectorTimeout(name) <= cfg.CPUSampleDuration {
+			return fmt.Errorf("%s timeout (%s) must be longer than cpu-sample (%s)", name, cfg.collectorTimeout(name), cfg.CPUSampleDuration)
+		}
+	}
+	// Catch a mistyped disk once at startup instead of failing every tick
+	if seen["disk"] {
+		if _, err := os.Stat(cfg.DiskDrive); err != nil {
+			return fmt.Errorf("disk path %q is not accessible: %w", cfg.DiskDrive, err)
+		}
+	}
+	return nil
+}
+
+// setDuration parses a Go duration string such as "1s" or "250ms".
+func setDuration(target *time.Duration, value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("not a duration (use values like 500ms or 2s)")
+	}
+	*target = d
+	return nil
+}
+
+// setHook sets the hook for one metric, or removes it for an empty command,
+// so a flag can drop a hook from the config file.
+func setHook(cfg *AppConfig, name, command string) {
+	name = strings.TrimSpace(strings.ToLower(name))
+	command = strings.TrimSpace(command)
+	if command == "" {
+		delete(cfg.Hooks, name)
+		return
+	}
+	if cfg.Hooks == nil {
+		cfg.Hooks = make(map[string]string)
+	}
+	cfg.Hooks[name] = command
+}
+
+// setByteSize parses a size like 512KB, 100MB or 2GB; units are 1024 apart,
+// as in the UI, so MiB-style suffixes mean the same. A plain number is bytes.
+func setByteSize(target *int64, value string) error {
+	value = strings.TrimSpace(value)
+	number, unit := value, ""
+	if i := strings.IndexFunc(value, func(r rune) bool { return (r < '0' || r > '9') && r != '.' }); i >= 0 {
+		number, unit = value[:i], strings.ToUpper(strings.TrimSpace(value[i:]))
+	}
+	unit = strings.TrimSuffix(strings.TrimSuffix(unit, "B"), "I") // MiB, MB and M are all M
+
+	power := 0
+	if unit != "" {
+		power = strings.Index("KMGT", unit) + 1
+	}
+	size, err := strconv.ParseFloat(number, 64)
+	if err != nil || size <= 0 || len(unit) > 1 || (unit != "" && power == 0) {
+		return fmt.Errorf("%q is not a size (use values like 512KB or 100MB)", value)
+	}
+	*target = int64(size * math.Pow(1024, float64(power)))
+	return nil
+}
+
+// setBool parses a boolean such as "true", "false", "1" or "0".
+func setBool(target *bool, value string) error {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("not a boolean (use true or false)")
+	}
+	*target = b
+	return nil
+}
+
+// splitList splits a comma-separated list, trimming spaces and dropping empties.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// envName returns the environment variable for an option, e.g. HWMON_CPU_SAMPLE.
+func envName(option string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(option, "-", "_"))
+}
+
+// fileKey returns the config file key for an option, e.g. cpu_sample.
+func fileKey(option string) string {
+	return strings.ReplaceAll(option, "-", "_")
+}
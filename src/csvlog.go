@@ -0,0 +1,363 @@
+// Package main provides CSV logging for the hardware monitor.
+// This file contains the CSVLogger, which appends one row per snapshot to the
+// --csv file for spreadsheets and quick graphs, and rotates that file by day
+// or by size.
+//
+// The header never changes: values of disabled or failed collectors are left
+// empty rather than dropped, so every row lines up with the first. Lists -
+// disks, interfaces - are summed into totals; processes and mounts are left
+// to --record, which keeps the full snapshot.
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// csvTimeLayout is the time column format. Spreadsheets read it as a date,
+// which they don't for RFC 3339; unix_time has the unambiguous value.
+const csvTimeLayout = "2006-01-02 15:04:05"
+
+// csvRotatedLayout stamps rotated files with the time their first row was
+// written. It sorts in time order and is valid in Windows file names.
+const csvRotatedLayout = "2006-01-02T15-04-05"
+
+// csvColumn is one column of the CSV file.
+type csvColumn struct {
+	name      string
+	collector string // Empty cell unless this collector succeeded; "" for always filled
+	value     func(stats SystemStats) string
+}
+
+// csvColumns is the header, in order. Only ever append to it: anyone
+// reading the files by column position would be thrown off otherwise.
+var csvColumns = []csvColumn{
+	{"time", "", func(s SystemStats) string { return s.Time.Local().Format(csvTimeLayout) }},
+	{"unix_time", "", func(s SystemStats) string { return csvFloat(float64(s.Time.UnixMilli()) / 1000) }},
+	{"duration_ms", "", func(s SystemStats) string { return csvFloat(float64(s.Duration) / float64(time.Millisecond)) }},
+	{"cpu_usage", "cpu", func(s SystemStats) string { return csvFloat(s.CPUUsage) }},
+	{"memory_usage", "memory", func(s SystemStats) string { return csvFloat(s.MemoryUsage) }},
+	{"memory_used_gb", "memory", func(s SystemStats) string { return csvFloat(s.MemoryUsed) }},
+	{"memory_total_gb", "memory", func(s SystemStats) string { return csvFloat(s.MemoryTotal) }},
+	{"swap_usage", "memory", func(s SystemStats) string { return csvFloat(s.SwapUsage) }},
+	{"swap_used_gb", "memory", func(s SystemStats) string { return csvFloat(s.SwapUsed) }},
+	{"swap_total_gb", "memory", func(s SystemStats) string { return csvFloat(s.SwapTotal) }},
+	{"disk_usage", "disk", func(s SystemStats) string { return csvFloat(s.DiskUsage) }},
+	{"disk_used_gb", "disk", func(s SystemStats) string { return csvFloat(s.DiskUsed) }},
+	{"disk_total_gb", "disk", func(s SystemStats) string { return csvFloat(s.DiskTotal) }},
+	{"disk_path", "disk", func(s SystemStats) string { return s.DiskPath }},
+	{"disk_read_bytes_per_second", "diskio", func(s SystemStats) string {
+		return csvSum(s.DiskIO, func(d DiskIOInfo) float64 { return d.ReadBytesPerSec })
+	}},
+	{"disk_write_bytes_per_second", "diskio", func(s SystemStats) string {
+		return csvSum(s.DiskIO, func(d DiskIOInfo) float64 { return d.WriteBytesPerSec })
+	}},
+	{"net_rx_bytes_per_second", "network", func(s SystemStats) string {
+		return csvSum(s.Network, func(n NetInterfaceInfo) float64 { return n.RxBytesPerSec })
+	}},
+	{"net_tx_bytes_per_second", "network", func(s SystemStats) string {
+		return csvSum(s.Network, func(n NetInterfaceInfo) float64 { return n.TxBytesPerSec })
+	}},
+	{"load1", "host", func(s SystemStats) string { return csvFloat(s.Host.Load1) }},
+	{"load5", "host", func(s SystemStats) string { return csvFloat(s.Host.Load5) }},
+	{"load15", "host", func(s SystemStats) string { return csvFloat(s.Host.Load15) }},
+	{"alerts", "", func(s SystemStats) string { return strconv.Itoa(len(s.Alerts)) }},
+	{"failed", "", func(s SystemStats) string {
+		var failed []string
+		for _, name := range sortedKeys(s.Status) {
+			if s.Status[name].Failed() {
+				failed = append(failed, name)
+			}
+		}
+		return strings.Join(failed, " ")
+	}},
+}
+
+// csvHeader returns the column names.
+func csvHeader() []string {
+	header := make([]string, len(csvColumns))
+	for i, column := range csvColumns {
+		header[i] = column.name
+	}
+	return header
+}
+
+// csvRow returns the cells for one snapshot.
+func csvRow(stats SystemStats) []string {
+	row := make([]string, len(csvColumns))
+	for i, column := range csvColumns {
+		if column.collector == "" || stats.Succeeded(column.collector) {
+			row[i] = column.value(stats)
+		}
+	}
+	return row
+}
+
+// csvFloat formats a value without float noise; hundredths are plenty for a graph.
+func csvFloat(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+// csvSum totals one value over a list, e.g. the throughput of every interface.
+func csvSum[T any](items []T, value func(T) float64) string {
+	total := 0.0
+	for _, item := range items {
+		total += value(item)
+	}
+	return csvFloat(total)
+}
+
+// CSVRotation says when the CSV file is moved aside for a fresh one.
+// Either trigger may be off; with both off the file grows forever.
+type CSVRotation struct {
+	Daily   bool  // When the first snapshot of a new day comes in
+	MaxSize int64 // When the next row would take the file past this many bytes, 0 for no limit
+	Keep    int   // Rotated files to keep, oldest deleted first; 0 keeps them all
+}
+
+// CSVLogger is a Sink that appends one row per snapshot to a CSV file.
+// Every row is written straight to disk, so the file can be opened while the
+// monitor is still running. Rotated files are renamed with the time of their
+// first row, e.g. samples.csv becomes samples-2026-10-16T09-30-00.csv, and
+// a file left by an earlier run is appended to.
+type CSVLogger struct {
+	mu       sync.Mutex
+	path     string
+	rotation CSVRotation
+	file     *os.File
+	size     int64     // Bytes in the current file
+	empty    bool      // The current file holds just the header
+	started  time.Time // Time of the first row in the current file
+	err      error     // First write error; logging stops after it
+	closed   bool
+}
+
+// NewCSVLogger opens the CSV file at path, appending to it if it already
+// holds rows with the same header. A file with another header - written by
+// an older version - is rotated out of the way first.
+func NewCSVLogger(path string, rotation CSVRotation) (*CSVLogger, error) {
+	l := &CSVLogger{path: path, rotation: rotation}
+	if err := l.open(); err != nil {
+		return nil, fmt.Errorf("failed to open CSV file: %w", err)
+	}
+	return l, nil
+}
+
+// open opens or creates the file and writes the header to a new one.
+func (l *CSVLogger) open() error {
+	// EXISTING FILE - carry on where the last run stopped if the columns match
+	if info, err := os.Stat(l.path); err == nil && info.Size() > 0 {
+		header, first := readCSVStart(l.path)
+		started := info.ModTime() // Good enough if the first row can't be read
+		if len(first) > 1 {
+			if seconds, err := strconv.ParseFloat(first[1], 64); err == nil {
+				started = time.UnixMilli(int64(seconds * 1000))
+			}
+		}
+		if slices.Equal(header, csvHeader()) {
+			file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				return err
+			}
+			l.file, l.size, l.empty, l.started = file, info.Size(), first == nil, started
+			return nil
+		}
+		if err := l.rotate(started); err != nil {
+			return err
+		}
+	}
+
+	// NEW FILE - the header goes in before the first row
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	l.file, l.size, l.empty = file, 0, true
+	return l.write(csvLine(csvHeader()))
+}
+
+// readCSVStart returns the header and the first row of the CSV file at path,
+// nil for either that can't be read.
+func readCSVStart(path string) (header, first []string) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1 // A file written by another version may differ
+	header, _ = reader.Read()
+	first, _ = reader.Read()
+	return header, first
+}
+
+// csvLine encodes one record as a line of CSV.
+func csvLine(record []string) string {
+	var line strings.Builder
+	w := csv.NewWriter(&line)
+	w.Write(record)
+	w.Flush()
+	return line.String()
+}
+
+// write appends a line to the file, keeping track of its size.
+func (l *CSVLogger) write(line string) error {
+	n, err := io.WriteString(l.file, line)
+	l.size += int64(n)
+	return err
+}
+
+// Observe implements Sink by appending the snapshot as a row, rotating the
+// file first when it is due.
+func (l *CSVLogger) Observe(stats SystemStats) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed || l.err != nil {
+		return // Keep the file as it was before the first failure
+	}
+
+	line := csvLine(csvRow(stats))
+	if l.rotationDue(stats.Time, int64(len(line))) {
+		if err := l.file.Close(); err != nil {
+			l.err = err
+			return
+		}
+		if err := l.rotate(l.started); err != nil {
+			l.err = err
+			return
+		}
+		if err := l.open(); err != nil {
+			l.err = err
+			return
+		}
+	}
+
+	if l.empty {
+		l.started = stats.Time
+		l.empty = false
+	}
+	l.err = l.write(line)
+}
+
+// rotationDue reports whether a row of rowSize bytes for a snapshot taken at
+// now belongs in a fresh file. A file is never rotated before its first row,
+// so a row bigger than MaxSize still gets written.
+func (l *CSVLogger) rotationDue(now time.Time, rowSize int64) bool {
+	if l.empty {
+		return false
+	}
+	if l.rotation.MaxSize > 0 && l.size+rowSize > l.rotation.MaxSize {
+		return true
+	}
+	if l.rotation.Daily {
+		y1, m1, d1 := l.started.Local().Date()
+		y2, m2, d2 := now.Local().Date()
+		return y1 != y2 || m1 != m2 || d1 != d2
+	}
+	return false
+}
+
+// rotate renames the (closed) file after the time of its first row and
+// deletes the oldest rotated files beyond Keep.
+func (l *CSVLogger) rotate(started time.Time) error {
+	ext := filepath.Ext(l.path)
+	stem := strings.TrimSuffix(l.path, ext)
+
+	// RENAME - a file started within the same second as another gets a counter
+	stamp := started.Local().Format(csvRotatedLayout)
+	target := stem + "-" + stamp + ext
+	for i := 1; ; i++ {
+		if _, err := os.Stat(target); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		target = fmt.Sprintf("%s-%s.%d%s", stem, stamp, i, ext)
+	}
+	if err := os.Rename(l.path, target); err != nil {
+		return err
+	}
+
+	// PRUNE - the oldest go first
+	if l.rotation.Keep <= 0 {
+		return nil
+	}
+	rotated, err := l.rotatedFiles()
+	if err != nil {
+		return err
+	}
+	for len(rotated) > l.rotation.Keep {
+		if err := os.Remove(rotated[0]); err != nil {
+			return err
+		}
+		rotated = rotated[1:]
+	}
+	return nil
+}
+
+// rotatedFiles lists the rotated files of this logger, oldest first.
+func (l *CSVLogger) rotatedFiles() ([]string, error) {
+	ext := filepath.Ext(l.path)
+	stem := strings.TrimSuffix(filepath.Base(l.path), ext)
+	pattern := regexp.MustCompile(`^` + regexp.QuoteMeta(stem) +
+		`-\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}(\.\d+)?` + regexp.QuoteMeta(ext) + `$`)
+
+	entries, err := os.ReadDir(filepath.Dir(l.path))
+	if err != nil {
+		return nil, err
+	}
+	// The time a file was last written to orders them even when the stamps
+	// are equal, as they are for files with a counter
+	type rotatedFile struct {
+		path     string
+		modified time.Time
+	}
+	var files []rotatedFile
+	for _, entry := range entries {
+		if !pattern.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, rotatedFile{filepath.Join(filepath.Dir(l.path), entry.Name()), info.ModTime()})
+	}
+	slices.SortStableFunc(files, func(a, b rotatedFile) int { return a.modified.Compare(b.modified) })
+
+	rotated := make([]string, len(files))
+	for i, f := range files {
+		rotated[i] = f.path
+	}
+	return rotated, nil
+}
+
+// Close closes the file and reports the first error seen while logging.
+func (l *CSVLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return l.err
+	}
+	l.closed = true
+
+	if err := l.file.Close(); err != nil && l.err == nil {
+		l.err = err
+	}
+	if l.err != nil {
+		return fmt.Errorf("CSV logging to %s failed: %w", l.path, l.err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// csvFrames returns snapshots one minute apart starting at start.
+func csvFrames(start time.Time, n int) []SystemStats {
+	frames := make([]SystemStats, n)
+	for i := range frames {
+		frames[i] = SystemStats{Time: start.Add(time.Duration(i) * time.Minute), CPUUsage: 12.345, DiskUsage: 50}
+		frames[i].setStatus("cpu", nil)
+		frames[i].setStatus("disk", nil)
+	}
+	return frames
+}
+
+// logCSV writes frames through a new logger and returns the records of path.
+func logCSV(t *testing.T, path string, rotation CSVRotation, frames []SystemStats) [][]string {
+	t.Helper()
+	logger, err := NewCSVLogger(path, rotation)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, stats := range frames {
+		logger.Observe(stats)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return readCSV(t, path)
+}
+
+// readCSV returns every record of the CSV file at path.
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected a valid CSV file, got %v", err)
+	}
+	return records
+}
+
+// column returns the index of the named column.
+func column(name string) int {
+	return slices.Index(csvHeader(), name)
+}
+
+func TestCSVLogger(t *testing.T) {
+	start := time.Date(2026, 10, 16, 22, 0, 0, 0, time.Local)
+
+	t.Run("Rows", func(t *testing.T) {
+		// Arrange - the disk fails in the second snapshot
+		frames := csvFrames(start, 2)
+		frames[1].setStatus("disk", errors.New("disk unplugged"))
+
+		// Act
+		records := logCSV(t, filepath.Join(t.TempDir(), "samples.csv"), CSVRotation{}, frames)
+
+		// Assert - failed and disabled collectors leave their cells empty
+		if len(records) != 3 || !slices.Equal(records[0], csvHeader()) {
+			t.Fatalf("Expected the header and 2 rows, got %q", records)
+		}
+		row := records[1]
+		if row[column("time")] != "2026-10-16 22:00:00" || row[column("cpu_usage")] != "12.35" || row[column("disk_usage")] != "50" {
+			t.Errorf("Unexpected first row %q", row)
+		}
+		if row[column("memory_usage")] != "" || row[column("failed")] != "" {
+			t.Errorf("Expected no memory value and no failures, got %q", row)
+		}
+		if row := records[2]; row[column("disk_usage")] != "" || row[column("failed")] != "disk" {
+			t.Errorf("Expected the failed disk to be reported, got %q", row)
+		}
+	})
+
+	t.Run("NoBaseline", func(t *testing.T) {
+		// Arrange - the first snapshot has no rates yet
+		frames := csvFrames(start, 1)
+		frames[0].setStatus("diskio", ErrNoBaseline)
+		frames[0].setStatus("network", ErrNoBaseline)
+
+		// Act
+		records := logCSV(t, filepath.Join(t.TempDir(), "samples.csv"), CSVRotation{}, frames)
+
+		// Assert
+		row := records[1]
+		if row[column("disk_read_bytes_per_second")] != "" || row[column("net_rx_bytes_per_second")] != "" || row[column("failed")] != "" {
+			t.Errorf("Expected no rates and no failures, got %q", row)
+		}
+	})
+
+	t.Run("Appends", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "samples.csv")
+		logCSV(t, path, CSVRotation{}, csvFrames(start, 2))
+
+		records := logCSV(t, path, CSVRotation{}, csvFrames(start.Add(time.Hour), 1))
+
+		if len(records) != 4 || slices.Equal(records[3], csvHeader()) {
+			t.Errorf("Expected the next run to append a row without a second header, got %q", records)
+		}
+	})
+
+	t.Run("HeaderChanged", func(t *testing.T) {
+		// Arrange - a file with columns this version doesn't write
+		dir := t.TempDir()
+		path := filepath.Join(dir, "samples.csv")
+		os.WriteFile(path, []byte("time,cpu\n2026-10-15 08:00:00,5\n"), 0o644)
+
+		// Act
+		records := logCSV(t, path, CSVRotation{}, csvFrames(start, 1))
+
+		// Assert - the old file is kept under another name
+		if len(records) != 2 || !slices.Equal(records[0], csvHeader()) {
+			t.Errorf("Expected a fresh file, got %q", records)
+		}
+		if rotated, _ := filepath.Glob(filepath.Join(dir, "samples-*.csv")); len(rotated) != 1 {
+			t.Errorf("Expected the old file to be rotated, got %v", rotated)
+		}
+	})
+
+	t.Run("RotatesDaily", func(t *testing.T) {
+		// Arrange - 22:00 to 01:00, crossing midnight
+		dir := t.TempDir()
+		path := filepath.Join(dir, "samples.csv")
+		frames := csvFrames(start, 1)
+		frames = append(frames, csvFrames(start.Add(90*time.Minute), 1)...)
+		frames = append(frames, csvFrames(start.Add(3*time.Hour), 1)...)
+
+		// Act
+		records := logCSV(t, path, CSVRotation{Daily: true}, frames)
+
+		// Assert
+		if len(records) != 2 || records[1][column("time")] != "2026-10-17 01:00:00" {
+			t.Errorf("Expected only the new day in the current file, got %q", records)
+		}
+		old := filepath.Join(dir, "samples-2026-10-16T22-00-00.csv")
+		if records := readCSV(t, old); len(records) != 3 {
+			t.Errorf("Expected both rows of the old day in %s, got %q", old, records)
+		}
+	})
+
+	t.Run("RotatesBySize", func(t *testing.T) {
+		// Arrange - room for the header and about two rows
+		dir := t.TempDir()
+		path := filepath.Join(dir, "samples.csv")
+		header := int64(len(strings.Join(csvHeader(), ",")) + 1)
+		row := int64(len(csvLine(csvRow(csvFrames(start, 1)[0]))))
+		rotation := CSVRotation{MaxSize: header + 2*row, Keep: 2}
+
+		// Act
+		records := logCSV(t, path, rotation, csvFrames(start, 9))
+
+		// Assert - 9 rows make 5 files, of which the 2 newest rotated ones are kept
+		if len(records) != 2 {
+			t.Errorf("Expected the last row in the current file, got %q", records)
+		}
+		rotated, _ := filepath.Glob(filepath.Join(dir, "samples-*.csv"))
+		want := []string{
+			filepath.Join(dir, "samples-2026-10-16T22-04-00.csv"),
+			filepath.Join(dir, "samples-2026-10-16T22-06-00.csv"),
+		}
+		if !slices.Equal(rotated, want) {
+			t.Errorf("Expected %v, got %v", want, rotated)
+		}
+	})
+
+	t.Run("ObserveAfterClose", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "samples.csv")
+		logger, _ := NewCSVLogger(path, CSVRotation{})
+		logger.Close()
+		logger.Observe(csvFrames(start, 1)[0]) // The sampler may still finish a collection
+
+		if records := readCSV(t, path); len(records) != 1 {
+			t.Errorf("Expected just the header, got %q", records)
+		}
+	})
+}
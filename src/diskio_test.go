@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/disk"
+)
+
+func TestGopsutilMonitorDiskIO(t *testing.T) {
+	t.Run("FirstCall", func(t *testing.T) {
+		monitor := NewGopsutilMonitor(Providers{Disk: mockDiskProvider{ioCounters: map[string]disk.IOCountersStat{
+			"sda":   {Name: "sda", ReadCount: 10, ReadBytes: 4096},
+			"loop0": {Name: "loop0"},
+		}}})
+
+		devices, err := monitor.GetDiskIO(nil)
+
+		// No baseline yet - reported as pending rather than as idle devices
+		if !errors.Is(err, ErrNoBaseline) || devices != nil {
+			t.Errorf("Expected ErrNoBaseline, got %+v and %v", devices, err)
+		}
+
+		// The second call has one; the unused loop device is skipped
+		devices, err = monitor.GetDiskIO(nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(devices) != 1 || devices[0].Name != "sda" || devices[0].ReadBytesPerSec != 0 {
+			t.Errorf("Expected an idle sda only, got %+v", devices)
+		}
+	})
+
+	t.Run("Rates", func(t *testing.T) {
+		// Arrange - a baseline 2 seconds ago
+		monitor := NewGopsutilMonitor(Providers{Disk: mockDiskProvider{ioCounters: map[string]disk.IOCountersStat{
+			"sdb": {Name: "sdb", ReadCount: 1},
+			"sda": {
+				Name:      "sda",
+				ReadCount: 120, WriteCount: 60,
+				ReadBytes: 1 << 21, WriteBytes: 1 << 20,
+				ReadTime: 300, WriteTime: 700,
+				IoTime: 1500,
+			},
+		}}}).(*GopsutilMonitor)
+		monitor.diskIOBaseline = diskIOBaseline{
+			at: time.Now().Add(-2 * time.Second),
+			counters: map[string]disk.IOCountersStat{
+				"sda": {Name: "sda", ReadCount: 100, WriteCount: 30, IoTime: 500, ReadTime: 100, WriteTime: 100},
+				"sdb": {Name: "sdb", ReadCount: 1},
+			},
+		}
+
+		// Act
+		devices, err := monitor.GetDiskIO(nil)
+
+		// Assert - sorted by name; 20 reads and 30 writes taking 800ms in total
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(devices) != 2 || devices[0].Name != "sda" || devices[1].Name != "sdb" {
+			t.Fatalf("Expected sda and sdb in order, got %+v", devices)
+		}
+		sda := devices[0]
+		if math.Abs(sda.ReadBytesPerSec-(1<<20)) > 1<<15 || math.Abs(sda.WriteBytesPerSec-(1<<19)) > 1<<14 {
+			t.Errorf("Expected about 1 MiB/s read and 512 KiB/s written, got %+v", sda)
+		}
+		if math.Abs(sda.ReadIOPS-10) > 1 || math.Abs(sda.WriteIOPS-15) > 1 {
+			t.Errorf("Expected about 10 read and 15 write IOPS, got %+v", sda)
+		}
+		if math.Abs(sda.UtilPercent-50) > 2 {
+			t.Errorf("Expected about 50%% utilization, got %f", sda.UtilPercent)
+		}
+		if sda.AwaitMs != 16 {
+			t.Errorf("Expected 16ms await, got %f", sda.AwaitMs)
+		}
+		if devices[1].AwaitMs != 0 || devices[1].UtilPercent != 0 {
+			t.Errorf("Expected no await or utilization for an idle device, got %+v", devices[1])
+		}
+	})
+
+	t.Run("UtilizationCapped", func(t *testing.T) {
+		monitor := NewGopsutilMonitor(Providers{Disk: mockDiskProvider{ioCounters: map[string]disk.IOCountersStat{
+			"sda": {Name: "sda", ReadCount: 2, IoTime: 5000},
+		}}}).(*GopsutilMonitor)
+		monitor.diskIOBaseline = diskIOBaseline{
+			at:       time.Now().Add(-time.Second),
+			counters: map[string]disk.IOCountersStat{"sda": {Name: "sda", ReadCount: 1}},
+		}
+
+		devices, err := monitor.GetDiskIO(nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if devices[0].UtilPercent != 100 {
+			t.Errorf("Expected utilization capped at 100%%, got %f", devices[0].UtilPercent)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		monitor := NewGopsutilMonitor(Providers{Disk: mockDiskProvider{ioErr: errors.New("no diskstats")}})
+
+		if _, err := monitor.GetDiskIO(nil); err == nil {
+			t.Error("Expected error, got nil")
+		}
+	})
+}
+
+func TestDiskIOSummary(t *testing.T) {
+	// Arrange
+	originalPlaces := config.DecimalPlaces
+	config.DecimalPlaces = 1
+	defer func() { config.DecimalPlaces = originalPlaces }()
+	devices := []DiskIOInfo{
+		{Name: "sda", ReadBytesPerSec: 1024, UtilPercent: 5},
+		{Name: "nvme0n1", WriteBytesPerSec: 2048, UtilPercent: 42},
+	}
+
+	// Act & Assert
+	if got := diskIOSummary(devices); got != "read 1.0 KiB/s, write 2.0 KiB/s, busiest nvme0n1 at 42.0%" {
+		t.Errorf("Unexpected summary %q", got)
+	}
+	if got := diskIOSummary(nil); got != "read 0.0 B/s, write 0.0 B/s" {
+		t.Errorf("Unexpected summary without devices %q", got)
+	}
+}
@@ -3,47 +3,194 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"sync" // For WaitGroup concurrency coordination
+	"time"
 )
 
 // SystemStats holds real-time system monitoring data.
 // It groups related hardware metrics for easy handling and display.
+// The JSON tags define the format of headless output (--output=jsonl).
 type SystemStats struct {
-	CPUUsage    float64 // CPU percentage (0-100)
-	MemoryUsage float64 // Memory percentage (0-100)
-	MemoryUsed  float64 // Memory used in GB
-	MemoryTotal float64 // Total memory in GB
-	DiskUsage   float64 // Disk percentage (0-100)
-	DiskUsed    float64 // Disk used in GB
-	DiskTotal   float64 // Total disk space in GB
+	Time     time.Time     `json:"time"`        // When the collection finished
+	Duration time.Duration `json:"duration_ns"` // How long the collection took
+
+	CPUUsage    float64   `json:"cpu_usage"`            // CPU percentage (0-100)
+	CoreUsage   []float64 `json:"core_usage,omitempty"` // CPU percentage (0-100) per logical CPU
+	MemoryUsage float64   `json:"memory_usage"`         // Memory percentage (0-100)
+	MemoryUsed  float64   `json:"memory_used_gb"`       // Memory used in GB
+	MemoryTotal float64   `json:"memory_total_gb"`      // Total memory in GB
+	SwapUsage   float64   `json:"swap_usage"`           // Swap percentage (0-100)
+	SwapUsed    float64   `json:"swap_used_gb"`         // Swap used in GB
+	SwapTotal   float64   `json:"swap_total_gb"`        // Total swap in GB, 0 without swap
+	DiskUsage   float64   `json:"disk_usage"`           // Disk percentage (0-100)
+	DiskUsed    float64   `json:"disk_used_gb"`         // Disk used in GB
+	DiskTotal   float64   `json:"disk_total_gb"`        // Total disk space in GB
+	DiskPath    string    `json:"disk_path,omitempty"`  // Path the disk values are for
+
+	MemoryDetail *MemoryInfo `json:"memory_detail,omitempty"` // Full memory breakdown in bytes (memory collector)
+
+	Mounts    []MountInfo        `json:"mounts,omitempty"`    // Every reported mounted filesystem (mounts collector)
+	DiskIO    []DiskIOInfo       `json:"disk_io,omitempty"`   // Per-device I/O activity (diskio collector)
+	Processes []ProcessInfo      `json:"processes,omitempty"` // Busiest processes, sorted by CPU (processes collector)
+	Network   []NetInterfaceInfo `json:"network,omitempty"`   // Per-interface throughput (network collector)
+	Host      *HostInfo          `json:"host,omitempty"`      // Load averages and machine identity (host collector)
+
+	// Alerts are filled in by the AlertEngine, if alert rules are configured
+	Alerts      []Alert `json:"alerts,omitempty"`       // Every active alert, most severe first
+	AlertEvents []Alert `json:"alert_events,omitempty"` // Alerts that fired, changed level or resolved in this snapshot
+
+	// Status records how each enabled collector fared, keyed by collector name.
+	// A failed collector leaves its fields at zero, so check this before trusting them.
+	Status map[string]MetricStatus `json:"status"`
+}
+
+// MetricState says whether a collector's values in a snapshot can be trusted.
+type MetricState string
+
+const (
+	StateOK      MetricState = "ok"      // Collected successfully this time
+	StateError   MetricState = "error"   // The collector failed
+	StateStale   MetricState = "stale"   // No fresh value: the collector timed out or was cancelled
+	StatePending MetricState = "pending" // No value yet: rates need a second sample
+)
+
+// MetricStatus is the outcome of one collector in a snapshot.
+type MetricStatus struct {
+	State MetricState `json:"state"`
+	Error string      `json:"error,omitempty"` // Why the collector failed, empty when State is StateOK
+}
+
+// Failed reports whether the collector failed or timed out. A collector
+// still waiting for its baseline hasn't failed, it just has nothing yet.
+func (m MetricStatus) Failed() bool {
+	return m.State == StateError || m.State == StateStale
+}
+
+// StatusOf returns the status of the named collector.
+// Collectors that didn't run (e.g. disabled ones) report ok == false.
+func (s SystemStats) StatusOf(name string) (status MetricStatus, ok bool) {
+	status, ok = s.Status[name]
+	return status, ok
+}
+
+// Succeeded reports whether the named collector ran and succeeded,
+// i.e. whether its fields hold real values.
+func (s SystemStats) Succeeded(name string) bool {
+	status, ok := s.Status[name]
+	return ok && status.State == StateOK
+}
+
+// LastError returns the error message of the last failed collector, in
+// configured collector order, or "" if everything succeeded.
+func (s SystemStats) LastError() string {
+	message := ""
+	for _, name := range config.Collectors {
+		if status, ok := s.Status[name]; ok && status.Failed() {
+			message = status.Error
+		}
+	}
+	return message
+}
+
+// setStatus records the outcome of the named collector.
+func (s *SystemStats) setStatus(name string, err error) {
+	if s.Status == nil {
+		s.Status = make(map[string]MetricStatus)
+	}
+
+	switch {
+	case err == nil:
+		s.Status[name] = MetricStatus{State: StateOK}
+	case errors.Is(err, ErrNoBaseline):
+		s.Status[name] = MetricStatus{State: StatePending, Error: err.Error()}
+	case errors.Is(err, ErrCollectorTimeout), errors.Is(err, context.Canceled):
+		// Probably transient - the next collection may well succeed
+		s.Status[name] = MetricStatus{State: StateStale, Error: err.Error()}
+	default:
+		s.Status[name] = MetricStatus{State: StateError, Error: err.Error()}
+	}
 }
 
 // MetricResult represents the result of a single metric collection operation.
 // It provides proper error handling instead of using sentinel values.
 type MetricResult struct {
-	Type  string      // Metric type: "cpu", "memory", or "disk"
+	Type  string      // Name of the collector that produced the result
 	Value interface{} // The actual metric data
 	Error error       // Any error that occurred during collection
 }
 
+// Clock is implemented by monitors whose snapshots belong to another time,
+// such as ReplayMonitor. fetchSystemStats stamps their snapshots with Now
+// instead of the wall clock.
+type Clock interface {
+	Now() time.Time
+}
+
+// ErrCollectorTimeout is reported in a MetricResult when a collector doesn't
+// finish within its configured timeout. Check for it with errors.Is.
+var ErrCollectorTimeout = errors.New("collector timed out")
+
+// ErrNoBaseline is reported by collectors that compute rates, such as
+// network throughput, on their first call: there is no previous reading to
+// compare with yet, and zeros would look like an idle machine.
+var ErrNoBaseline = errors.New("no baseline yet, rates need a second sample")
+
+// rateCollectors report activity since their previous call, so their first
+// snapshot holds no rates.
+var rateCollectors = []string{"diskio", "network", "processes"}
+
+// fetchSettledStats collects one snapshot for the one-shot subcommands. If a
+// rate collector is enabled, a baseline is taken first and the snapshot
+// follows one RefreshInterval later, so its rates cover a real interval.
+func fetchSettledStats(ctx context.Context, monitor SystemMonitor) SystemStats {
+	statsCh := make(chan SystemStats, 1)
+	needsBaseline := slices.ContainsFunc(config.Collectors, func(name string) bool {
+		return slices.Contains(rateCollectors, name)
+	})
+	if needsBaseline {
+		fetchSystemStats(ctx, monitor, statsCh)
+		<-statsCh
+		select {
+		case <-time.After(config.RefreshInterval):
+		case <-ctx.Done():
+		}
+	}
+	fetchSystemStats(ctx, monitor, statsCh)
+	return <-statsCh
+}
+
 // fetchSystemStats gathers all system statistics using WaitGroup coordination.
-// It demonstrates proper Go concurrency patterns with error handling.
-func fetchSystemStats(monitor SystemMonitor, statsCh chan SystemStats) {
+// Every enabled collector from the registry runs in its own goroutine, and each
+// result is routed back to its collector to be applied to the stats snapshot.
+// Cancelling ctx abandons every collector that is still running.
+func fetchSystemStats(ctx context.Context, monitor SystemMonitor, statsCh chan SystemStats) {
 	// Create empty stats struct to fill with data
 	var stats SystemStats
+	start := time.Now()
+
+	// Resolve the configured collector names into collectors
+	enabled, err := collectors.Enabled(config.Collectors)
+	if err != nil {
+		// Nothing runs, so every configured metric reports the problem
+		for _, name := range config.Collectors {
+			stats.setStatus(name, err)
+		}
+	}
 
 	// WAITGROUP COORDINATION - Better than manual channel management
 	var wg sync.WaitGroup
-	results := make(chan MetricResult, config.ResultsBuffer) // Buffered channel for all results
+	results := make(chan MetricResult, len(enabled)) // Buffered channel for all results
 
-	// START ALL GOROUTINES WITH WAITGROUP COORDINATION
+	// START ONE GOROUTINE PER COLLECTOR
 	// Each goroutine will signal completion via wg.Done()
-	wg.Add(config.MetricCount) // We're starting configured number of goroutines
-
-	go fetchCPUMetric(monitor, &wg, results)    // Goroutine 1: Get CPU data
-	go fetchMemoryMetric(monitor, &wg, results) // Goroutine 2: Get memory data
-	go fetchDiskMetric(monitor, &wg, results)   // Goroutine 3: Get disk data
+	wg.Add(len(enabled))
+	for _, c := range enabled {
+		go runCollector(ctx, c, monitor, &wg, results)
+	}
 
 	// WAIT FOR ALL GOROUTINES TO COMPLETE
 	// This is safer than waiting for channels individually
@@ -55,94 +202,61 @@ func fetchSystemStats(monitor SystemMonitor, statsCh chan SystemStats) {
 	// COLLECT AND PROCESS ALL RESULTS
 	// Range over channel until it's closed
 	for result := range results {
+		// Record the outcome so the display can tell a real 0% from a failure
+		stats.setStatus(result.Type, result.Error)
 		if result.Error != nil {
-			// Log error but continue with other metrics
-			log.Printf("Error fetching %s metric: %v", result.Type, result.Error)
-			continue
+			continue // Keep going with the other metrics
 		}
 
-		// Process successful results based on type
-		// Now we work with our clean interface types!
-		switch result.Type {
-		case "cpu":
-			if cpuUsage, ok := result.Value.(float64); ok {
-				stats.CPUUsage = cpuUsage
-			}
-		case "memory":
-			// Now we get clean MemoryInfo instead of gopsutil's VirtualMemoryStat
-			if memInfo, ok := result.Value.(*MemoryInfo); ok {
-				stats.MemoryUsage = memInfo.UsedPercent
-				// Convert bytes to gigabytes using config constant
-				stats.MemoryUsed = float64(memInfo.Used) / float64(config.BytesToGB)
-				stats.MemoryTotal = float64(memInfo.Total) / float64(config.BytesToGB)
-			}
-		case "disk":
-			// Now we get clean DiskInfo instead of gopsutil's UsageStat
-			if diskInfo, ok := result.Value.(*DiskInfo); ok {
-				stats.DiskUsage = diskInfo.UsedPercent
-				// Convert bytes to gigabytes using config constant
-				stats.DiskUsed = float64(diskInfo.Used) / float64(config.BytesToGB)
-				stats.DiskTotal = float64(diskInfo.Total) / float64(config.BytesToGB)
-			}
+		// Let the collector that produced the result decide where it goes
+		if c, ok := collectors.Lookup(result.Type); ok {
+			c.Apply(result, &stats)
 		}
 	}
 
 	// SEND COMPLETE STATS - Send our filled struct to the waiting function
+	stats.Time = time.Now()
+	stats.Duration = stats.Time.Sub(start)
+	if clock, ok := monitor.(Clock); ok {
+		stats.Time = clock.Now() // A replayed snapshot keeps the time it was recorded at
+	}
 	statsCh <- stats
 }
 
-// fetchCPUMetric retrieves CPU usage using the provided SystemMonitor interface.
-// This demonstrates interface usage - we don't know or care what implementation is used!
-func fetchCPUMetric(monitor SystemMonitor, wg *sync.WaitGroup, results chan<- MetricResult) {
+// runCollector runs a single collector and sends its result to the results channel.
+// This demonstrates interface usage - we don't know or care which metric is collected!
+// If the collector outlives its timeout we stop waiting and report ErrCollectorTimeout.
+func runCollector(ctx context.Context, c Collector, monitor SystemMonitor, wg *sync.WaitGroup, results chan<- MetricResult) {
 	// ALWAYS call Done() when function exits - use defer for safety
 	defer wg.Done()
 
-	// USE THE INTERFACE! This is the key change.
-	// We call monitor.GetCPUUsage instead of cpu.Percent directly
-	// The function doesn't know if it's talking to GopsutilMonitor, MockMonitor, etc.
-	cpuUsage, err := monitor.GetCPUUsage(config.CPUSampleDuration)
-	if err != nil {
-		// Interface already wrapped the error nicely
-		results <- MetricResult{Type: "cpu", Value: nil, Error: err}
-		return
-	}
-
-	// Success! Send the clean result
-	results <- MetricResult{Type: "cpu", Value: cpuUsage, Error: nil}
-}
+	timeout := config.collectorTimeout(c.Name())
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-// fetchMemoryMetric retrieves memory usage using the provided SystemMonitor interface.
-// Clean and simple - just like the CPU version!
-func fetchMemoryMetric(monitor SystemMonitor, wg *sync.WaitGroup, results chan<- MetricResult) {
-	// ALWAYS call Done() when function exits - use defer for safety
-	defer wg.Done()
+	// Buffered so an abandoned collector can still finish and exit later
+	done := make(chan MetricResult, 1)
+	go func() {
+		done <- c.Collect(ctx, monitor)
+	}()
 
-	// USE THE INTERFACE! Much simpler than the old version
-	memoryInfo, err := monitor.GetMemoryUsage()
-	if err != nil {
-		// Interface already wrapped the error nicely
-		results <- MetricResult{Type: "memory", Value: nil, Error: err}
-		return
+	select {
+	case result := <-done:
+		// A context-aware call may notice the deadline before we do
+		if errors.Is(result.Error, context.DeadlineExceeded) {
+			result.Error = timeoutError(c.Name(), timeout)
+		}
+		results <- result
+	case <-ctx.Done():
+		err := ctx.Err()
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = timeoutError(c.Name(), timeout)
+		}
+		results <- MetricResult{Type: c.Name(), Value: nil, Error: err}
 	}
-
-	// Success! Send the clean result
-	results <- MetricResult{Type: "memory", Value: memoryInfo, Error: nil}
 }
 
-// fetchDiskMetric retrieves disk usage using the provided SystemMonitor interface.
-// Clean and consistent with other interface-based functions!
-func fetchDiskMetric(monitor SystemMonitor, wg *sync.WaitGroup, results chan<- MetricResult) {
-	// ALWAYS call Done() when function exits - use defer for safety
-	defer wg.Done()
-
-	// USE THE INTERFACE! Consistent pattern across all metrics
-	diskInfo, err := monitor.GetDiskUsage(config.DiskDrive)
-	if err != nil {
-		// Interface already wrapped the error nicely
-		results <- MetricResult{Type: "disk", Value: nil, Error: err}
-		return
-	}
-
-	// Success! Send the clean result
-	results <- MetricResult{Type: "disk", Value: diskInfo, Error: nil}
+// timeoutError builds the error reported for a collector that ran out of time.
+func timeoutError(name string, timeout time.Duration) error {
+	return fmt.Errorf("%s: %w after %s", name, ErrCollectorTimeout, timeout)
 }
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io/fs"
 	"sync"
 	"testing"
 	"time"
@@ -9,12 +12,25 @@ import (
 
 // MockSystemMonitor for testing purposes
 type MockSystemMonitor struct {
-	CPUUsage    float64
-	CPUError    error
-	MemoryInfo  *MemoryInfo
-	MemoryError error
-	DiskInfo    *DiskInfo
-	DiskError   error
+	CPUUsage     float64
+	CPUError     error
+	CoreUsage    []float64
+	CoreError    error
+	MemoryInfo   *MemoryInfo
+	MemoryError  error
+	DiskInfo     *DiskInfo
+	DiskError    error
+	DiskCalls    int // Number of GetDiskUsage calls, for retry tests
+	Mounts       []MountInfo
+	MountError   error
+	DiskIO       []DiskIOInfo
+	DiskIOError  error
+	Processes    []ProcessInfo
+	ProcessError error
+	Network      []NetInterfaceInfo
+	NetworkError error
+	Host         *HostInfo
+	HostError    error
 }
 
 func (m *MockSystemMonitor) GetCPUUsage(duration time.Duration) (float64, error) {
@@ -24,6 +40,13 @@ func (m *MockSystemMonitor) GetCPUUsage(duration time.Duration) (float64, error)
 	return m.CPUUsage, nil
 }
 
+func (m *MockSystemMonitor) GetPerCoreUsage(duration time.Duration) ([]float64, error) {
+	if m.CoreError != nil {
+		return nil, m.CoreError
+	}
+	return m.CoreUsage, nil
+}
+
 func (m *MockSystemMonitor) GetMemoryUsage() (*MemoryInfo, error) {
 	if m.MemoryError != nil {
 		return nil, m.MemoryError
@@ -32,12 +55,48 @@ func (m *MockSystemMonitor) GetMemoryUsage() (*MemoryInfo, error) {
 }
 
 func (m *MockSystemMonitor) GetDiskUsage(path string) (*DiskInfo, error) {
+	m.DiskCalls++
 	if m.DiskError != nil {
 		return nil, m.DiskError
 	}
 	return m.DiskInfo, nil
 }
 
+func (m *MockSystemMonitor) GetMountUsage(filter MountFilter) ([]MountInfo, error) {
+	if m.MountError != nil {
+		return nil, m.MountError
+	}
+	return m.Mounts, nil
+}
+
+func (m *MockSystemMonitor) GetDiskIO(devices []string) ([]DiskIOInfo, error) {
+	if m.DiskIOError != nil {
+		return nil, m.DiskIOError
+	}
+	return m.DiskIO, nil
+}
+
+func (m *MockSystemMonitor) GetProcesses(limit int) ([]ProcessInfo, error) {
+	if m.ProcessError != nil {
+		return nil, m.ProcessError
+	}
+	return m.Processes, nil
+}
+
+func (m *MockSystemMonitor) GetNetworkUsage() ([]NetInterfaceInfo, error) {
+	if m.NetworkError != nil {
+		return nil, m.NetworkError
+	}
+	return m.Network, nil
+}
+
+func (m *MockSystemMonitor) GetHostInfo() (*HostInfo, error) {
+	if m.HostError != nil {
+		return nil, m.HostError
+	}
+	return m.Host, nil
+}
+
 func TestFetchSystemStats(t *testing.T) {
 	// Test successful data collection
 	t.Run("Success", func(t *testing.T) {
@@ -47,6 +106,10 @@ func TestFetchSystemStats(t *testing.T) {
 				UsedPercent: 60.0,
 				Used:        8 * 1024 * 1024 * 1024,  // 8GB
 				Total:       16 * 1024 * 1024 * 1024, // 16GB
+
+				SwapUsedPercent: 25.0,
+				SwapUsed:        1 * 1024 * 1024 * 1024, // 1GB
+				SwapTotal:       4 * 1024 * 1024 * 1024, // 4GB
 			},
 			DiskInfo: &DiskInfo{
 				UsedPercent: 45.0,
@@ -56,7 +119,7 @@ func TestFetchSystemStats(t *testing.T) {
 		}
 
 		statsCh := make(chan SystemStats, 1)
-		fetchSystemStats(mock, statsCh)
+		fetchSystemStats(context.Background(), mock, statsCh)
 
 		select {
 		case stats := <-statsCh:
@@ -72,6 +135,12 @@ func TestFetchSystemStats(t *testing.T) {
 			if stats.MemoryTotal != 16.0 {
 				t.Errorf("Expected memory total 16.0GB, got %fGB", stats.MemoryTotal)
 			}
+			if stats.SwapUsage != 25.0 || stats.SwapUsed != 1.0 || stats.SwapTotal != 4.0 {
+				t.Errorf("Expected swap 25%% (1GB / 4GB), got %f%% (%fGB / %fGB)", stats.SwapUsage, stats.SwapUsed, stats.SwapTotal)
+			}
+			if stats.MemoryDetail == nil || stats.MemoryDetail.SwapTotal != 4*1024*1024*1024 {
+				t.Errorf("Expected the memory breakdown to be kept, got %+v", stats.MemoryDetail)
+			}
 			if stats.DiskUsage != 45.0 {
 				t.Errorf("Expected disk usage 45.0%%, got %f%%", stats.DiskUsage)
 			}
@@ -96,7 +165,7 @@ func TestFetchSystemStats(t *testing.T) {
 		}
 
 		statsCh := make(chan SystemStats, 1)
-		fetchSystemStats(mock, statsCh)
+		fetchSystemStats(context.Background(), mock, statsCh)
 
 		select {
 		case stats := <-statsCh:
@@ -111,6 +180,16 @@ func TestFetchSystemStats(t *testing.T) {
 			if stats.DiskUsage != 0 {
 				t.Errorf("Expected disk usage 0 due to error, got %f", stats.DiskUsage)
 			}
+			// ...and the status says why, so the zeros aren't mistaken for readings
+			if status, _ := stats.StatusOf("cpu"); status.State != StateOK {
+				t.Errorf("Expected cpu status ok, got %+v", status)
+			}
+			if status, _ := stats.StatusOf("memory"); status.State != StateError || status.Error != "memory error" {
+				t.Errorf("Expected memory status error with message, got %+v", status)
+			}
+			if stats.LastError() != "disk error" {
+				t.Errorf("Expected last error from disk, got %q", stats.LastError())
+			}
 		case <-time.After(2 * time.Second):
 			t.Fatal("Timeout waiting for stats")
 		}
@@ -127,7 +206,7 @@ func TestFetchCPUMetric(t *testing.T) {
 		results := make(chan MetricResult, 1)
 
 		wg.Add(1)
-		go fetchCPUMetric(mock, &wg, results)
+		go runCollector(context.Background(), cpuCollector{}, mock, &wg, results)
 		wg.Wait()
 		close(results)
 
@@ -154,7 +233,7 @@ func TestFetchCPUMetric(t *testing.T) {
 		results := make(chan MetricResult, 1)
 
 		wg.Add(1)
-		go fetchCPUMetric(mock, &wg, results)
+		go runCollector(context.Background(), cpuCollector{}, mock, &wg, results)
 		wg.Wait()
 		close(results)
 
@@ -187,7 +266,7 @@ func TestFetchMemoryMetric(t *testing.T) {
 		results := make(chan MetricResult, 1)
 
 		wg.Add(1)
-		go fetchMemoryMetric(mock, &wg, results)
+		go runCollector(context.Background(), memoryCollector{}, mock, &wg, results)
 		wg.Wait()
 		close(results)
 
@@ -219,7 +298,7 @@ func TestFetchMemoryMetric(t *testing.T) {
 		results := make(chan MetricResult, 1)
 
 		wg.Add(1)
-		go fetchMemoryMetric(mock, &wg, results)
+		go runCollector(context.Background(), memoryCollector{}, mock, &wg, results)
 		wg.Wait()
 		close(results)
 
@@ -252,7 +331,7 @@ func TestFetchDiskMetric(t *testing.T) {
 		results := make(chan MetricResult, 1)
 
 		wg.Add(1)
-		go fetchDiskMetric(mock, &wg, results)
+		go runCollector(context.Background(), &diskCollector{}, mock, &wg, results)
 		wg.Wait()
 		close(results)
 
@@ -284,7 +363,7 @@ func TestFetchDiskMetric(t *testing.T) {
 		results := make(chan MetricResult, 1)
 
 		wg.Add(1)
-		go fetchDiskMetric(mock, &wg, results)
+		go runCollector(context.Background(), &diskCollector{}, mock, &wg, results)
 		wg.Wait()
 		close(results)
 
@@ -301,6 +380,84 @@ func TestFetchDiskMetric(t *testing.T) {
 	})
 }
 
+func TestDiskCollectorMissingPath(t *testing.T) {
+	// Arrange - the disk call fails the way a missing mount does
+	mock := &MockSystemMonitor{
+		DiskError: fmt.Errorf("statfs: %w", fs.ErrNotExist),
+	}
+	collector := &diskCollector{}
+
+	// Act - collect several times
+	first := collector.Collect(context.Background(), mock)
+	second := collector.Collect(context.Background(), mock)
+
+	// Assert - the path is queried once and the error says we gave up
+	if first.Error == nil || second.Error == nil {
+		t.Fatal("Expected errors for missing disk path")
+	}
+	if !contains(second.Error.Error(), "not retrying") {
+		t.Errorf("Error should say the path is not retried: %v", second.Error)
+	}
+	if mock.DiskCalls != 1 {
+		t.Errorf("Expected 1 disk call for a missing path, got %d", mock.DiskCalls)
+	}
+}
+
+func TestRunCollectorTimeout(t *testing.T) {
+	// Arrange - a short timeout and a CPU call that never returns on its own
+	originalTimeouts := config.CollectorTimeouts
+	config.CollectorTimeouts = map[string]time.Duration{"cpu": 50 * time.Millisecond}
+	defer func() { config.CollectorTimeouts = originalTimeouts }()
+
+	monitor := &blockingMonitor{
+		MockSystemMonitor: &MockSystemMonitor{CPUUsage: 42.0},
+		release:           make(chan struct{}),
+	}
+	defer close(monitor.release) // Let the abandoned call finish
+
+	t.Run("Deadline", func(t *testing.T) {
+		var wg sync.WaitGroup
+		results := make(chan MetricResult, 1)
+
+		// Act
+		start := time.Now()
+		wg.Add(1)
+		go runCollector(context.Background(), cpuCollector{}, monitor, &wg, results)
+		wg.Wait()
+		result := <-results
+
+		// Assert - we stop waiting at the deadline and say so
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Expected runCollector to give up after its timeout, took %s", elapsed)
+		}
+		if !errors.Is(result.Error, ErrCollectorTimeout) {
+			t.Errorf("Expected ErrCollectorTimeout, got %v", result.Error)
+		}
+		if result.Type != "cpu" || result.Value != nil {
+			t.Errorf("Expected empty cpu result, got %+v", result)
+		}
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var wg sync.WaitGroup
+		results := make(chan MetricResult, 1)
+
+		// Act
+		wg.Add(1)
+		go runCollector(ctx, cpuCollector{}, monitor, &wg, results)
+		wg.Wait()
+		result := <-results
+
+		// Assert - cancellation is not reported as a timeout
+		if !errors.Is(result.Error, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", result.Error)
+		}
+	})
+}
+
 func TestSystemStats(t *testing.T) {
 	// Test the SystemStats struct can be created and populated
 	stats := SystemStats{
@@ -359,3 +516,136 @@ func TestMetricResult(t *testing.T) {
 		t.Error("Expected error, got nil")
 	}
 }
+
+// fakeCollector is a test collector that reports a fixed CPU value.
+type fakeCollector struct {
+	name  string
+	value float64
+}
+
+func (f fakeCollector) Name() string { return f.name }
+
+func (f fakeCollector) Collect(ctx context.Context, monitor SystemMonitor) MetricResult {
+	return MetricResult{Type: f.name, Value: f.value}
+}
+
+func (f fakeCollector) Apply(result MetricResult, stats *SystemStats) {
+	stats.CPUUsage = result.Value.(float64)
+}
+
+func TestCollectorRegistry(t *testing.T) {
+	t.Run("Builtins", func(t *testing.T) {
+		names := collectors.Names()
+		expected := []string{"cpu", "cores", "memory", "disk"}
+		if len(names) < len(expected) {
+			t.Fatalf("Expected at least %d collectors, got %v", len(expected), names)
+		}
+		for i, name := range expected {
+			if names[i] != name {
+				t.Errorf("Expected collector %d to be %q, got %q", i, name, names[i])
+			}
+		}
+	})
+
+	t.Run("DuplicateName", func(t *testing.T) {
+		registry := NewCollectorRegistry()
+		if err := registry.Register(fakeCollector{name: "fake"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := registry.Register(fakeCollector{name: "fake"}); err == nil {
+			t.Error("Expected error registering duplicate collector, got nil")
+		}
+	})
+
+	t.Run("UnknownName", func(t *testing.T) {
+		registry := NewCollectorRegistry()
+		if _, err := registry.Enabled([]string{"nope"}); err == nil {
+			t.Error("Expected error for unknown collector, got nil")
+		}
+	})
+}
+
+func TestFetchSystemStatsWithRegistry(t *testing.T) {
+	// Restore the global registry and config after the test
+	originalRegistry, originalCollectors := collectors, config.Collectors
+	defer func() {
+		collectors, config.Collectors = originalRegistry, originalCollectors
+	}()
+
+	mock := &MockSystemMonitor{
+		CPUUsage:   10.0,
+		MemoryInfo: &MemoryInfo{UsedPercent: 60.0},
+		DiskInfo:   &DiskInfo{UsedPercent: 45.0},
+	}
+
+	t.Run("DisabledCollector", func(t *testing.T) {
+		collectors = newDefaultRegistry()
+		config.Collectors = []string{"cpu", "memory"}
+
+		statsCh := make(chan SystemStats, 1)
+		fetchSystemStats(context.Background(), mock, statsCh)
+		stats := <-statsCh
+
+		if stats.MemoryUsage != 60.0 {
+			t.Errorf("Expected memory usage 60.0%%, got %f%%", stats.MemoryUsage)
+		}
+		if stats.DiskUsage != 0 {
+			t.Errorf("Expected disk usage 0 when disabled, got %f", stats.DiskUsage)
+		}
+		if _, ok := stats.StatusOf("disk"); ok {
+			t.Error("Expected no status for a disabled collector")
+		}
+	})
+
+	t.Run("CustomCollector", func(t *testing.T) {
+		collectors = newDefaultRegistry()
+		if err := RegisterCollector(fakeCollector{name: "fake", value: 99.0}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		config.Collectors = []string{"fake"}
+
+		statsCh := make(chan SystemStats, 1)
+		fetchSystemStats(context.Background(), mock, statsCh)
+		stats := <-statsCh
+
+		if stats.CPUUsage != 99.0 {
+			t.Errorf("Expected custom collector value 99.0, got %f", stats.CPUUsage)
+		}
+	})
+}
+
+func TestSystemStatsSetStatus(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		state MetricState
+	}{
+		{name: "OK", err: nil, state: StateOK},
+		{name: "Error", err: errors.New("boom"), state: StateError},
+		{name: "Timeout", err: timeoutError("cpu", time.Second), state: StateStale},
+		{name: "Cancelled", err: context.Canceled, state: StateStale},
+		{name: "NoBaseline", err: ErrNoBaseline, state: StatePending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var stats SystemStats
+
+			// Act
+			stats.setStatus("cpu", tt.err)
+
+			// Assert
+			status, ok := stats.StatusOf("cpu")
+			if !ok {
+				t.Fatal("Expected a status for cpu")
+			}
+			if status.State != tt.state {
+				t.Errorf("Expected state %q, got %q", tt.state, status.State)
+			}
+			if (tt.err == nil) != (status.Error == "") {
+				t.Errorf("Expected error message only on failure, got %q", status.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Package main provides sample history for the hardware monitor.
+// This file contains a fixed-size ring buffer of recent values and the
+// downsampling used to fit a long history into a narrow sparkline.
+package main
+
+// RingBuffer keeps the most recent samples of one metric.
+// Once full, each new sample overwrites the oldest, so memory use stays fixed
+// no matter how long the monitor runs.
+type RingBuffer struct {
+	values []float64
+	next   int // Index the next sample is written to
+	count  int // Samples stored so far, up to len(values)
+}
+
+// NewRingBuffer creates a buffer holding up to capacity samples.
+func NewRingBuffer(capacity int) *RingBuffer {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &RingBuffer{values: make([]float64, capacity)}
+}
+
+// Add stores a sample, dropping the oldest one if the buffer is full.
+func (r *RingBuffer) Add(value float64) {
+	r.values[r.next] = value
+	r.next = (r.next + 1) % len(r.values)
+	if r.count < len(r.values) {
+		r.count++
+	}
+}
+
+// Resize changes how many samples the buffer holds, keeping the newest ones
+// that still fit - e.g. when the refresh interval changes but the history
+// should still cover the same time.
+func (r *RingBuffer) Resize(capacity int) {
+	capacity = max(capacity, 1)
+	values := r.Values()
+	if len(values) > capacity {
+		values = values[len(values)-capacity:]
+	}
+
+	r.values = make([]float64, capacity)
+	r.count = copy(r.values, values)
+	r.next = r.count % capacity
+}
+
+// Len returns the number of samples stored.
+func (r *RingBuffer) Len() int {
+	return r.count
+}
+
+// Cap returns the most samples the buffer can hold.
+func (r *RingBuffer) Cap() int {
+	return len(r.values)
+}
+
+// Values returns a copy of the stored samples, oldest first.
+func (r *RingBuffer) Values() []float64 {
+	out := make([]float64, 0, r.count)
+	start := (r.next - r.count + len(r.values)) % len(r.values)
+	for i := 0; i < r.count; i++ {
+		out = append(out, r.values[(start+i)%len(r.values)])
+	}
+	return out
+}
+
+// Max returns the largest stored sample, or 0 if the buffer is empty.
+func (r *RingBuffer) Max() float64 {
+	peak := 0.0
+	for i, value := range r.Values() {
+		if i == 0 || value > peak {
+			peak = value
+		}
+	}
+	return peak
+}
+
+// downsampleMax shrinks values to at most width points, keeping the maximum
+// of each group - averaging would flatten exactly the spikes we want to see.
+func downsampleMax(values []float64, width int) []float64 {
+	if width < 1 {
+		return nil
+	}
+	if len(values) <= width {
+		return values
+	}
+
+	out := make([]float64, width)
+	for i := range out {
+		// Each output point covers values[from:to], spread evenly over the input
+		from := i * len(values) / width
+		to := (i + 1) * len(values) / width
+		out[i] = values[from]
+		for _, value := range values[from:to] {
+			out[i] = max(out[i], value)
+		}
+	}
+	return out
+}
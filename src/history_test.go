@@ -0,0 +1,98 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRingBuffer(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		r := NewRingBuffer(3)
+		if r.Len() != 0 || len(r.Values()) != 0 {
+			t.Errorf("Expected empty buffer, got %v", r.Values())
+		}
+		if r.Max() != 0 {
+			t.Errorf("Expected max 0 for empty buffer, got %f", r.Max())
+		}
+	})
+
+	t.Run("PartiallyFilled", func(t *testing.T) {
+		r := NewRingBuffer(3)
+		r.Add(1)
+		r.Add(2)
+
+		if got := r.Values(); !reflect.DeepEqual(got, []float64{1, 2}) {
+			t.Errorf("Expected [1 2], got %v", got)
+		}
+	})
+
+	t.Run("Wraps", func(t *testing.T) {
+		// Arrange & Act - five samples into three slots
+		r := NewRingBuffer(3)
+		for _, v := range []float64{1, 5, 3, 4, 2} {
+			r.Add(v)
+		}
+
+		// Assert - only the newest three remain, oldest first
+		if got := r.Values(); !reflect.DeepEqual(got, []float64{3, 4, 2}) {
+			t.Errorf("Expected [3 4 2], got %v", got)
+		}
+		if r.Len() != 3 || r.Cap() != 3 {
+			t.Errorf("Expected len 3 and cap 3, got %d and %d", r.Len(), r.Cap())
+		}
+		if r.Max() != 4 {
+			t.Errorf("Expected max 4 once 5 dropped out, got %f", r.Max())
+		}
+	})
+
+	t.Run("MinimumCapacity", func(t *testing.T) {
+		r := NewRingBuffer(0)
+		r.Add(7)
+		if got := r.Values(); !reflect.DeepEqual(got, []float64{7}) {
+			t.Errorf("Expected [7], got %v", got)
+		}
+	})
+
+	t.Run("Resize", func(t *testing.T) {
+		// Arrange - a wrapped buffer
+		r := NewRingBuffer(3)
+		for _, v := range []float64{1, 2, 3, 4} {
+			r.Add(v)
+		}
+
+		// Act & Assert - shrinking keeps the newest samples
+		r.Resize(2)
+		if got := r.Values(); !reflect.DeepEqual(got, []float64{3, 4}) {
+			t.Errorf("Expected [3 4], got %v", got)
+		}
+
+		// Growing keeps everything and adds room
+		r.Resize(4)
+		r.Add(5)
+		if got := r.Values(); !reflect.DeepEqual(got, []float64{3, 4, 5}) || r.Cap() != 4 {
+			t.Errorf("Expected [3 4 5] with cap 4, got %v with cap %d", got, r.Cap())
+		}
+	})
+}
+
+func TestDownsampleMax(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		width  int
+		want   []float64
+	}{
+		{name: "Fits", values: []float64{1, 2, 3}, width: 5, want: []float64{1, 2, 3}},
+		{name: "KeepsSpike", values: []float64{0, 0, 90, 0, 0, 0}, width: 3, want: []float64{0, 90, 0}},
+		{name: "Uneven", values: []float64{1, 2, 3, 4, 5}, width: 2, want: []float64{2, 5}},
+		{name: "NoWidth", values: []float64{1}, width: 0, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := downsampleMax(tt.values, tt.width); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
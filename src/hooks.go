@@ -0,0 +1,226 @@
+// Package main provides exec hooks for the hardware monitor.
+// This file contains the HookRunner, which runs a local command when an alert
+// fires, changes level or resolves - for example a log rotation script when
+// the disk fills up.
+//
+// The alert is passed to the command in environment variables:
+//
+//	HWMON_METRIC     full metric name, e.g. disk.used_percent
+//	HWMON_INSTANCE   mount, core, device or interface; empty for single values
+//	HWMON_VALUE      latest value of the metric
+//	HWMON_THRESHOLD  threshold of the level that was crossed
+//	HWMON_LEVEL      warning or critical
+//	HWMON_STATE      active or resolved
+//	HWMON_RULE       the rule as written, normalized
+//	HWMON_HOST       machine the alert is about
+//	HWMON_SINCE      when the alert fired, RFC 3339
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// hookAnyMetric is the --hook key that matches every alert.
+const hookAnyMetric = "*"
+
+// HookRunner is a Sink that runs the configured command for every alert event.
+// Commands run one at a time on a background goroutine, in the order the
+// events happened, so a cleanup script never races its own "resolved" run.
+type HookRunner struct {
+	hooks    map[string]string // Command by full metric name or hookAnyMetric
+	hostname string            // Used when a snapshot doesn't carry host info
+	queue    chan hookRun
+	logger   *log.Logger
+	logFile  *os.File // Nil when logging to the standard log
+	dropped  atomic.Uint64
+
+	// ctx kills the command in flight when Close gives up waiting
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{} // Closed once the queue has been worked off
+
+	mu     sync.Mutex // Guards closed against Observe racing Close
+	closed bool
+}
+
+// hookRun is one command to run for one alert event.
+type hookRun struct {
+	command string
+	alert   Alert
+	host    string
+}
+
+// NewHookRunner starts running hooks by metric, where a metric may be an
+// alias such as "disk" or hookAnyMetric. Output goes to the file at logPath,
+// or to the standard log if it is empty.
+func NewHookRunner(hooks map[string]string, logPath string) (*HookRunner, error) {
+	byMetric := make(map[string]string, len(hooks))
+	for name, command := range hooks {
+		metric, err := hookMetric(name)
+		if err != nil {
+			return nil, err
+		}
+		byMetric[metric] = command
+	}
+
+	r := &HookRunner{
+		hooks:  byMetric,
+		queue:  make(chan hookRun, config.HookQueue),
+		logger: log.Default(),
+		done:   make(chan struct{}),
+	}
+	if logPath != "" {
+		file, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open hook log: %w", err)
+		}
+		r.logFile = file
+		r.logger = log.New(file, "", log.LstdFlags)
+	}
+	r.hostname, _ = os.Hostname()
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+
+	go r.work()
+	return r, nil
+}
+
+// hookMetric resolves a --hook key to the full metric name it matches.
+func hookMetric(name string) (string, error) {
+	metric, ok := canonicalMetric(name)
+	if !ok && name != hookAnyMetric {
+		return "", fmt.Errorf("hook: unknown metric %q (known: %s, or %s for all)",
+			name, strings.Join(sortedKeys(alertMetrics), ", "), hookAnyMetric)
+	}
+	return metric, nil
+}
+
+// Observe implements Sink by queueing a run for every matching alert event.
+// It never blocks: a full queue loses its oldest run instead.
+func (r *HookRunner) Observe(stats SystemStats) {
+	host := r.hostname
+	if stats.Host != nil && stats.Host.Hostname != "" {
+		host = stats.Host.Hostname
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	for _, alert := range stats.AlertEvents {
+		// A metric's own hook first, then the catch-all
+		for _, key := range []string{alert.Metric, hookAnyMetric} {
+			command, ok := r.hooks[key]
+			if !ok {
+				continue
+			}
+			if enqueueDropOldest(r.queue, hookRun{command: command, alert: alert, host: host}) {
+				r.dropped.Add(1)
+				r.logger.Printf("hook: queue full, dropped the oldest run")
+			}
+		}
+	}
+}
+
+// Close stops accepting events and waits up to the hook timeout for queued
+// runs to finish, then kills the command in flight and skips the rest.
+func (r *HookRunner) Close() error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-r.done:
+	case <-time.After(config.HookTimeout):
+		r.cancel()
+		<-r.done
+	}
+	r.cancel()
+
+	if r.logFile != nil {
+		return r.logFile.Close()
+	}
+	return nil
+}
+
+// work runs queued hooks one after another until the queue is closed.
+func (r *HookRunner) work() {
+	defer close(r.done)
+	for run := range r.queue {
+		if r.ctx.Err() != nil {
+			continue // Shutting down - skip what is left
+		}
+		r.run(run)
+	}
+}
+
+// run executes one hook through the shell and logs how it went, followed by
+// everything it printed.
+func (r *HookRunner) run(run hookRun) {
+	ctx, cancel := context.WithTimeout(r.ctx, config.HookTimeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, run.command) // See hooks_unix.go and hooks_other.go
+	cmd.Env = append(os.Environ(), hookEnv(run.alert, run.host)...)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// Don't wait long for output from anything that survived the kill
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	outcome := "ok"
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		outcome = fmt.Sprintf("killed after the %s timeout", config.HookTimeout)
+	case ctx.Err() != nil:
+		outcome = "killed at shutdown"
+	case err != nil:
+		outcome = err.Error()
+	}
+	name := run.alert.Name()
+	r.logger.Printf("hook %q for %s %s %s: %s in %s", run.command, name, run.alert.Level, run.alert.State, outcome, elapsed)
+	for _, line := range strings.Split(strings.TrimRight(output.String(), "\n"), "\n") {
+		if line != "" {
+			r.logger.Printf("hook %s | %s", name, line)
+		}
+	}
+}
+
+// hookEnv returns the environment variables describing an alert, sorted by name.
+func hookEnv(alert Alert, host string) []string {
+	values := map[string]string{
+		"METRIC":    alert.Metric,
+		"INSTANCE":  alert.Instance,
+		"VALUE":     strconv.FormatFloat(alert.Value, 'f', -1, 64),
+		"THRESHOLD": strconv.FormatFloat(alert.Threshold, 'f', -1, 64),
+		"LEVEL":     alert.Level.String(),
+		"STATE":     string(alert.State),
+		"RULE":      alert.Rule,
+		"HOST":      host,
+		"SINCE":     alert.Since.Format(time.RFC3339),
+	}
+	env := make([]string, 0, len(values))
+	for name, value := range values {
+		env = append(env, envPrefix+name+"="+value)
+	}
+	sort.Strings(env)
+	return env
+}
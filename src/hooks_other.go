@@ -0,0 +1,17 @@
+//go:build !unix
+
+// Package main provides exec hooks for the hardware monitor.
+// This file contains how hooks are started on Windows.
+package main
+
+import (
+	"context"
+	"os/exec"
+)
+
+// shellCommand runs command through cmd.exe, so hooks can use arguments,
+// pipes and redirections. A timeout kills the shell; programs it started
+// are left alone.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "cmd", "/C", command)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runHooks runs hooks for the given alert events and returns the hook log.
+func runHooks(t *testing.T, hooks map[string]string, events ...Alert) string {
+	t.Helper()
+	logPath := filepath.Join(t.TempDir(), "hooks.log")
+	runner, err := NewHookRunner(hooks, logPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	runner.Observe(alertEvents(events...))
+	if err := runner.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, _ := os.ReadFile(logPath)
+	return string(data)
+}
+
+func TestHookRunner(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The hooks below are POSIX shell commands")
+	}
+	original := config
+	defer func() { config = original }()
+	config.HookTimeout = 5 * time.Second
+
+	diskFull := Alert{Rule: "disk.used_percent > 90", Metric: "disk.used_percent", Instance: "/", Level: LevelCritical,
+		State: AlertActive, Value: 93.5, Threshold: 90, Since: time.Unix(1700000000, 0).UTC()}
+
+	t.Run("Environment", func(t *testing.T) {
+		// Arrange
+		out := filepath.Join(t.TempDir(), "env")
+		hook := `printf '%s|%s|%s|%s|%s|%s' "$HWMON_METRIC" "$HWMON_INSTANCE" "$HWMON_VALUE" "$HWMON_THRESHOLD" "$HWMON_STATE" "$HWMON_HOST" > ` + out
+
+		// Act
+		runHooks(t, map[string]string{"disk": hook}, diskFull)
+
+		// Assert
+		data, _ := os.ReadFile(out)
+		if want := "disk.used_percent|/|93.5|90|active|web-1"; string(data) != want {
+			t.Errorf("Expected %q, got %q", want, data)
+		}
+	})
+
+	t.Run("Matching", func(t *testing.T) {
+		dir := t.TempDir()
+		hooks := map[string]string{
+			"cpu":         "echo cpu >> " + filepath.Join(dir, "cpu"),
+			hookAnyMetric: "echo \"$HWMON_STATE\" >> " + filepath.Join(dir, "all"),
+		}
+		resolved := diskFull
+		resolved.State = AlertResolved
+
+		runHooks(t, hooks, diskFull, resolved)
+
+		if _, err := os.Stat(filepath.Join(dir, "cpu")); err == nil {
+			t.Error("Expected the cpu hook not to run for a disk alert")
+		}
+		if data, _ := os.ReadFile(filepath.Join(dir, "all")); string(data) != "active\nresolved\n" {
+			t.Errorf("Expected the catch-all hook to run for both events in order, got %q", data)
+		}
+	})
+
+	t.Run("Output", func(t *testing.T) {
+		logged := runHooks(t, map[string]string{"disk": "echo rotating; echo no space >&2; exit 3"}, diskFull)
+
+		for _, want := range []string{"disk.used_percent{/} critical active: exit status 3", "| rotating", "| no space"} {
+			if !strings.Contains(logged, want) {
+				t.Errorf("Expected %q in the hook log, got:\n%s", want, logged)
+			}
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		config.HookTimeout = 100 * time.Millisecond
+		defer func() { config.HookTimeout = 5 * time.Second }()
+
+		logPath := filepath.Join(t.TempDir(), "hooks.log")
+		runner, _ := NewHookRunner(nil, logPath)
+		defer runner.Close()
+
+		// Run directly - Close would give up on the hook at the same moment
+		start := time.Now()
+		runner.run(hookRun{command: "sleep 10 & wait", alert: diskFull})
+		data, _ := os.ReadFile(logPath)
+		logged := string(data)
+
+		if elapsed := time.Since(start); elapsed > 900*time.Millisecond {
+			t.Errorf("Expected the hook and its children to be killed, it ran for %s", elapsed)
+		}
+		if !strings.Contains(logged, "killed after the 100ms timeout") {
+			t.Errorf("Expected the timeout in the hook log, got:\n%s", logged)
+		}
+	})
+
+	t.Run("UnknownMetric", func(t *testing.T) {
+		if _, err := NewHookRunner(map[string]string{"gpu": "true"}, ""); err == nil || !strings.Contains(err.Error(), "unknown metric") {
+			t.Errorf("Expected an unknown metric error, got %v", err)
+		}
+	})
+}
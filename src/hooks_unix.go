@@ -0,0 +1,23 @@
+//go:build unix
+
+// Package main provides exec hooks for the hardware monitor.
+// This file contains how hooks are started on Unix-like systems.
+package main
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+)
+
+// shellCommand runs command through sh, so hooks can use arguments, pipes
+// and redirections. The hook gets its own process group, and a timeout kills
+// the whole group - otherwise a script's children would outlive it.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL) // Negative pid: the group
+	}
+	return cmd
+}
@@ -0,0 +1,143 @@
+// Package main provides host information for the hardware monitor.
+// This file contains the host provider and the host facts: load averages,
+// which change on every tick, and identity facts such as the hostname and
+// kernel, which are read rarely and cached in between.
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/host"
+	"github.com/shirou/gopsutil/v4/load"
+)
+
+// hostProvider wraps gopsutil host, load and CPU info functions
+type hostProvider interface {
+	InfoWithContext(ctx context.Context) (*host.InfoStat, error)
+	AvgWithContext(ctx context.Context) (*load.AvgStat, error)
+	CPUInfoWithContext(ctx context.Context) ([]cpu.InfoStat, error)
+	CountsWithContext(ctx context.Context, logical bool) (int, error)
+}
+
+// realHostProvider is the production hostProvider.
+type realHostProvider struct{}
+
+func (r realHostProvider) InfoWithContext(ctx context.Context) (*host.InfoStat, error) {
+	return host.InfoWithContext(ctx)
+}
+
+func (r realHostProvider) AvgWithContext(ctx context.Context) (*load.AvgStat, error) {
+	return load.AvgWithContext(ctx)
+}
+
+func (r realHostProvider) CPUInfoWithContext(ctx context.Context) ([]cpu.InfoStat, error) {
+	return cpu.InfoWithContext(ctx)
+}
+
+func (r realHostProvider) CountsWithContext(ctx context.Context, logical bool) (int, error) {
+	return cpu.CountsWithContext(ctx, logical)
+}
+
+// HostInfo describes the machine being monitored.
+type HostInfo struct {
+	Hostname      string    `json:"hostname"`
+	OS            string    `json:"os"`             // Distribution and version, e.g. "ubuntu 24.04"
+	Kernel        string    `json:"kernel"`         // Kernel version, e.g. "6.8.0-45-generic"
+	CPUModel      string    `json:"cpu_model"`      // e.g. "AMD Ryzen 7 7840U"
+	LogicalCores  int       `json:"logical_cores"`  // Hardware threads
+	PhysicalCores int       `json:"physical_cores"` // 0 if the OS doesn't say
+	BootTime      time.Time `json:"boot_time"`
+
+	// Load averages over 1, 5 and 15 minutes: runnable (and on Linux,
+	// uninterruptible) tasks, so 1.0 per logical core means fully busy
+	Load1  float64 `json:"load1"`
+	Load5  float64 `json:"load5"`
+	Load15 float64 `json:"load15"`
+}
+
+// Uptime returns how long the machine has been running at the given time.
+func (h *HostInfo) Uptime(now time.Time) time.Duration {
+	if h.BootTime.IsZero() {
+		return 0
+	}
+	return now.Sub(h.BootTime)
+}
+
+// LoadPercent returns a load average as a percentage of the logical cores,
+// so 100% means every core had work queued on average.
+func (h *HostInfo) LoadPercent(load float64) float64 {
+	if h.LogicalCores <= 0 {
+		return 0
+	}
+	return load / float64(h.LogicalCores) * 100
+}
+
+// GetHostInfo implements SystemMonitor interface for host information.
+// This wraps gopsutil host.Info, load.Avg and cpu.Info in our clean interface.
+func (g *GopsutilMonitor) GetHostInfo() (*HostInfo, error) {
+	return g.GetHostInfoContext(context.Background())
+}
+
+// GetHostInfoContext implements ContextMonitor for host information.
+// The load averages are read on every call; the identity facts are read on
+// the first call and again once they are older than config.HostInfoRefresh.
+func (g *GopsutilMonitor) GetHostInfoContext(ctx context.Context) (*HostInfo, error) {
+	avg, err := g.host.AvgWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get load average: %w", err)
+	}
+
+	g.hostMu.Lock()
+	defer g.hostMu.Unlock()
+
+	if g.hostIdentity == nil || time.Since(g.hostReadAt) >= config.HostInfoRefresh {
+		identity, err := readHostIdentity(ctx, g.host)
+		if err != nil {
+			return nil, err
+		}
+		g.hostIdentity = identity
+		g.hostReadAt = time.Now()
+	}
+
+	// Copy the cached facts so callers can't change the cache
+	info := *g.hostIdentity
+	info.Load1, info.Load5, info.Load15 = avg.Load1, avg.Load5, avg.Load15
+	return &info, nil
+}
+
+// readHostIdentity reads the facts that rarely or never change.
+// Only the host info itself is required; CPU details are best effort
+// because some platforms and containers don't expose them.
+func readHostIdentity(ctx context.Context, provider hostProvider) (*HostInfo, error) {
+	hostStat, err := provider.InfoWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get host info: %w", err)
+	}
+
+	identity := &HostInfo{
+		Hostname: hostStat.Hostname,
+		OS:       strings.TrimSpace(hostStat.Platform + " " + hostStat.PlatformVersion),
+		Kernel:   hostStat.KernelVersion,
+	}
+	if identity.OS == "" {
+		identity.OS = hostStat.OS // e.g. "linux" when the distribution is unknown
+	}
+	if hostStat.BootTime > 0 {
+		identity.BootTime = time.Unix(int64(hostStat.BootTime), 0)
+	}
+
+	if infos, err := provider.CPUInfoWithContext(ctx); err == nil && len(infos) > 0 {
+		identity.CPUModel = strings.Join(strings.Fields(infos[0].ModelName), " ") // Some models are padded with spaces
+	}
+	if n, err := provider.CountsWithContext(ctx, true); err == nil {
+		identity.LogicalCores = n
+	}
+	if n, err := provider.CountsWithContext(ctx, false); err == nil {
+		identity.PhysicalCores = n
+	}
+	return identity, nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/host"
+	"github.com/shirou/gopsutil/v4/load"
+)
+
+// mockHostProvider returns fixed host facts and counts how often they are read
+type mockHostProvider struct {
+	info      *host.InfoStat
+	infoErr   error
+	avg       *load.AvgStat
+	avgErr    error
+	cpus      []cpu.InfoStat
+	cpuErr    error
+	infoCalls int
+}
+
+func (m *mockHostProvider) InfoWithContext(ctx context.Context) (*host.InfoStat, error) {
+	m.infoCalls++
+	return m.info, m.infoErr
+}
+
+func (m *mockHostProvider) AvgWithContext(ctx context.Context) (*load.AvgStat, error) {
+	return m.avg, m.avgErr
+}
+
+func (m *mockHostProvider) CPUInfoWithContext(ctx context.Context) ([]cpu.InfoStat, error) {
+	return m.cpus, m.cpuErr
+}
+
+func (m *mockHostProvider) CountsWithContext(ctx context.Context, logical bool) (int, error) {
+	if m.cpuErr != nil {
+		return 0, m.cpuErr
+	}
+	if logical {
+		return 8, nil
+	}
+	return 4, nil
+}
+
+func newMockHostProvider() *mockHostProvider {
+	return &mockHostProvider{
+		info: &host.InfoStat{
+			Hostname: "web1", Platform: "ubuntu", PlatformVersion: "24.04",
+			KernelVersion: "6.8.0", BootTime: 1700000000,
+		},
+		avg:  &load.AvgStat{Load1: 4, Load5: 2, Load15: 1},
+		cpus: []cpu.InfoStat{{ModelName: "  Example  CPU 3000 "}},
+	}
+}
+
+func TestGopsutilMonitorHostInfo(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		provider := newMockHostProvider()
+		monitor := NewGopsutilMonitor(Providers{Host: provider})
+
+		// Act
+		info, err := monitor.GetHostInfo()
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := HostInfo{
+			Hostname: "web1", OS: "ubuntu 24.04", Kernel: "6.8.0", CPUModel: "Example CPU 3000",
+			LogicalCores: 8, PhysicalCores: 4, BootTime: time.Unix(1700000000, 0),
+			Load1: 4, Load5: 2, Load15: 1,
+		}
+		if *info != want {
+			t.Errorf("Expected %+v, got %+v", want, *info)
+		}
+	})
+
+	t.Run("IdentityCached", func(t *testing.T) {
+		// Arrange
+		provider := newMockHostProvider()
+		monitor := NewGopsutilMonitor(Providers{Host: provider})
+
+		// Act - the load changes between calls, the identity doesn't
+		first, _ := monitor.GetHostInfo()
+		first.Hostname = "changed by caller"
+		provider.avg = &load.AvgStat{Load1: 9}
+		second, err := monitor.GetHostInfo()
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if provider.infoCalls != 1 {
+			t.Errorf("Expected host info to be read once, got %d reads", provider.infoCalls)
+		}
+		if second.Load1 != 9 {
+			t.Errorf("Expected fresh load 9, got %f", second.Load1)
+		}
+		if second.Hostname != "web1" {
+			t.Errorf("Expected the cache to be unaffected by callers, got %q", second.Hostname)
+		}
+	})
+
+	t.Run("IdentityRefreshed", func(t *testing.T) {
+		provider := newMockHostProvider()
+		monitor := NewGopsutilMonitor(Providers{Host: provider}).(*GopsutilMonitor)
+		monitor.GetHostInfo()
+
+		// Pretend the cached facts are older than the refresh interval
+		monitor.hostReadAt = time.Now().Add(-config.HostInfoRefresh)
+		monitor.GetHostInfo()
+
+		if provider.infoCalls != 2 {
+			t.Errorf("Expected host info to be re-read, got %d reads", provider.infoCalls)
+		}
+	})
+
+	t.Run("CPUDetailsOptional", func(t *testing.T) {
+		provider := newMockHostProvider()
+		provider.cpuErr = errors.New("not supported")
+		monitor := NewGopsutilMonitor(Providers{Host: provider})
+
+		info, err := monitor.GetHostInfo()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if info.CPUModel != "" || info.LogicalCores != 0 || info.Hostname != "web1" {
+			t.Errorf("Expected host facts without CPU details, got %+v", info)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			modify func(p *mockHostProvider)
+		}{
+			{name: "Load", modify: func(p *mockHostProvider) { p.avgErr = errors.New("no loadavg") }},
+			{name: "Info", modify: func(p *mockHostProvider) { p.infoErr = errors.New("no host info") }},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				provider := newMockHostProvider()
+				tt.modify(provider)
+				monitor := NewGopsutilMonitor(Providers{Host: provider})
+
+				if _, err := monitor.GetHostInfo(); err == nil {
+					t.Error("Expected error, got nil")
+				}
+			})
+		}
+	})
+}
+
+func TestHostSummaries(t *testing.T) {
+	// Arrange
+	h := &HostInfo{
+		Hostname: "web1", OS: "ubuntu 24.04", Kernel: "6.8.0", CPUModel: "Example CPU",
+		LogicalCores: 4, BootTime: time.Unix(1700000000, 0),
+		Load1: 2, Load5: 1, Load15: 0.5,
+	}
+	now := h.BootTime.Add(3*24*time.Hour + 4*time.Hour + 12*time.Minute)
+
+	// Act & Assert
+	if got := hostSummary(h, now); got != "web1 - ubuntu 24.04, kernel 6.8.0, up 3d 4h 12m" {
+		t.Errorf("Unexpected host summary %q", got)
+	}
+	if got := processorSummary(h); got != "Example CPU (4 threads)" {
+		t.Errorf("Unexpected processor summary %q", got)
+	}
+	if got := loadSummary(h); got != "2.00, 1.00, 0.50 (50%, 25%, 12% of 4 threads)" {
+		t.Errorf("Unexpected load summary %q", got)
+	}
+	if got := hostSummary(nil, now); got != "" {
+		t.Errorf("Expected nothing without host info, got %q", got)
+	}
+}
+
+func TestFormatUptime(t *testing.T) {
+	tests := []struct {
+		uptime time.Duration
+		want   string
+	}{
+		{uptime: 59 * time.Second, want: "0m"},
+		{uptime: 5*time.Hour + 7*time.Minute, want: "5h 7m"},
+		{uptime: 50 * time.Hour, want: "2d 2h 0m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := formatUptime(tt.uptime); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRealHostProvider(t *testing.T) {
+	// Every supported OS can name itself
+	info, err := realHostProvider{}.InfoWithContext(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.Hostname == "" {
+		t.Error("Expected a hostname")
+	}
+}
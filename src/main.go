@@ -3,14 +3,43 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"os"
 )
 
-// Global configuration - accessible from anywhere in this package
+// Global configuration - accessible from anywhere in this package.
+// Starts as the compiled-in defaults and is replaced by loadConfig in main.
 var config = Config
 
+// commands are subcommands that run once and exit with their own status,
+// e.g. "hw-monitor check -c disk=95". Without one the monitor starts.
+var commands = map[string]func(args []string) int{
+	"check":    func(args []string) int { return runCheck(args, os.Stdout, os.Stderr, os.LookupEnv) },
+	"snapshot": func(args []string) int { return runSnapshot(args, os.Stdout, os.Stderr, os.LookupEnv) },
+}
+
 // main - Entry point of our program, now completely focused on coordination
 func main() {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			os.Exit(command(os.Args[2:]))
+		}
+	}
+
+	// Load configuration from flags, environment and config file
+	cfg, err := loadConfig(os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return // Usage was already printed
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "hw-monitor: %v\n", err)
+		os.Exit(2)
+	}
+	config = cfg
+
 	// Create and setup the application (handles its own UI initialization)
 	app, err := newApp()
 	if err != nil {
@@ -3,7 +3,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/shirou/gopsutil/v4/cpu"
@@ -16,17 +19,20 @@ import (
 
 // cpuProvider wraps gopsutil cpu functions
 type cpuProvider interface {
-	Percent(duration time.Duration, percpu bool) ([]float64, error)
+	PercentWithContext(ctx context.Context, duration time.Duration, percpu bool) ([]float64, error)
 }
 
 // memProvider wraps gopsutil memory functions
 type memProvider interface {
-	VirtualMemory() (*mem.VirtualMemoryStat, error)
+	VirtualMemoryWithContext(ctx context.Context) (*mem.VirtualMemoryStat, error)
+	SwapMemoryWithContext(ctx context.Context) (*mem.SwapMemoryStat, error)
 }
 
 // diskProvider wraps gopsutil disk functions
 type diskProvider interface {
-	Usage(path string) (*disk.UsageStat, error)
+	UsageWithContext(ctx context.Context, path string) (*disk.UsageStat, error)
+	PartitionsWithContext(ctx context.Context, all bool) ([]disk.PartitionStat, error)
+	IOCountersWithContext(ctx context.Context, names ...string) (map[string]disk.IOCountersStat, error)
 }
 
 // Real implementations of the providers
@@ -34,16 +40,28 @@ type realCPUProvider struct{}
 type realMemProvider struct{}
 type realDiskProvider struct{}
 
-func (r realCPUProvider) Percent(duration time.Duration, percpu bool) ([]float64, error) {
-	return cpu.Percent(duration, percpu)
+func (r realCPUProvider) PercentWithContext(ctx context.Context, duration time.Duration, percpu bool) ([]float64, error) {
+	return cpu.PercentWithContext(ctx, duration, percpu)
 }
 
-func (r realMemProvider) VirtualMemory() (*mem.VirtualMemoryStat, error) {
-	return mem.VirtualMemory()
+func (r realMemProvider) VirtualMemoryWithContext(ctx context.Context) (*mem.VirtualMemoryStat, error) {
+	return mem.VirtualMemoryWithContext(ctx)
 }
 
-func (r realDiskProvider) Usage(path string) (*disk.UsageStat, error) {
-	return disk.Usage(path)
+func (r realMemProvider) SwapMemoryWithContext(ctx context.Context) (*mem.SwapMemoryStat, error) {
+	return mem.SwapMemoryWithContext(ctx)
+}
+
+func (r realDiskProvider) UsageWithContext(ctx context.Context, path string) (*disk.UsageStat, error) {
+	return disk.UsageWithContext(ctx, path)
+}
+
+func (r realDiskProvider) PartitionsWithContext(ctx context.Context, all bool) ([]disk.PartitionStat, error) {
+	return disk.PartitionsWithContext(ctx, all)
+}
+
+func (r realDiskProvider) IOCountersWithContext(ctx context.Context, names ...string) (map[string]disk.IOCountersStat, error) {
+	return disk.IOCountersWithContext(ctx, names...)
 }
 
 // SystemMonitor interface defines what we need from any monitoring system.
@@ -53,18 +71,115 @@ type SystemMonitor interface {
 	// GetCPUUsage returns CPU percentage (0-100) over the given duration
 	GetCPUUsage(duration time.Duration) (float64, error)
 
-	// GetMemoryUsage returns memory statistics
+	// GetPerCoreUsage returns one CPU percentage (0-100) per logical CPU over the given duration
+	GetPerCoreUsage(duration time.Duration) ([]float64, error)
+
+	// GetMemoryUsage returns memory and swap statistics
 	GetMemoryUsage() (*MemoryInfo, error)
 
 	// GetDiskUsage returns disk statistics for the given path
 	GetDiskUsage(path string) (*DiskInfo, error)
+
+	// GetMountUsage returns disk statistics for every mounted filesystem the filter allows
+	GetMountUsage(filter MountFilter) ([]MountInfo, error)
+
+	// GetDiskIO returns per-device I/O activity since the previous call (empty devices = all)
+	GetDiskIO(devices []string) ([]DiskIOInfo, error)
+
+	// GetProcesses returns the busiest processes: the top limit by CPU, memory and I/O
+	GetProcesses(limit int) ([]ProcessInfo, error)
+
+	// GetNetworkUsage returns per-interface throughput since the previous call
+	GetNetworkUsage() ([]NetInterfaceInfo, error)
+
+	// GetHostInfo returns the load averages and the identity of the machine
+	GetHostInfo() (*HostInfo, error)
+}
+
+// ContextMonitor is the cancellable counterpart of SystemMonitor.
+// Each method matches a SystemMonitor method but takes a context, so a slow
+// call can be abandoned when its collector times out or the app shuts down.
+// Implementing it is optional - see contextMonitor for the fallback.
+type ContextMonitor interface {
+	GetCPUUsageContext(ctx context.Context, duration time.Duration) (float64, error)
+	GetPerCoreUsageContext(ctx context.Context, duration time.Duration) ([]float64, error)
+	GetMemoryUsageContext(ctx context.Context) (*MemoryInfo, error)
+	GetDiskUsageContext(ctx context.Context, path string) (*DiskInfo, error)
+	GetMountUsageContext(ctx context.Context, filter MountFilter) ([]MountInfo, error)
+	GetDiskIOContext(ctx context.Context, devices []string) ([]DiskIOInfo, error)
+	GetProcessesContext(ctx context.Context, limit int) ([]ProcessInfo, error)
+	GetNetworkUsageContext(ctx context.Context) ([]NetInterfaceInfo, error)
+	GetHostInfoContext(ctx context.Context) (*HostInfo, error)
+}
+
+// contextMonitor returns the context-aware variant of a monitor.
+// Monitors that only implement SystemMonitor are adapted: the context is
+// ignored by the call itself, but the fetcher still stops waiting on timeout.
+func contextMonitor(monitor SystemMonitor) ContextMonitor {
+	if cm, ok := monitor.(ContextMonitor); ok {
+		return cm
+	}
+	return plainMonitorAdapter{monitor}
+}
+
+// plainMonitorAdapter gives a plain SystemMonitor the ContextMonitor methods.
+type plainMonitorAdapter struct {
+	SystemMonitor
+}
+
+func (a plainMonitorAdapter) GetCPUUsageContext(_ context.Context, duration time.Duration) (float64, error) {
+	return a.GetCPUUsage(duration)
+}
+
+func (a plainMonitorAdapter) GetPerCoreUsageContext(_ context.Context, duration time.Duration) ([]float64, error) {
+	return a.GetPerCoreUsage(duration)
 }
 
-// MemoryInfo holds clean memory statistics (wrapper around gopsutil data)
+func (a plainMonitorAdapter) GetMemoryUsageContext(_ context.Context) (*MemoryInfo, error) {
+	return a.GetMemoryUsage()
+}
+
+func (a plainMonitorAdapter) GetDiskUsageContext(_ context.Context, path string) (*DiskInfo, error) {
+	return a.GetDiskUsage(path)
+}
+
+func (a plainMonitorAdapter) GetMountUsageContext(_ context.Context, filter MountFilter) ([]MountInfo, error) {
+	return a.GetMountUsage(filter)
+}
+
+func (a plainMonitorAdapter) GetDiskIOContext(_ context.Context, devices []string) ([]DiskIOInfo, error) {
+	return a.GetDiskIO(devices)
+}
+
+func (a plainMonitorAdapter) GetProcessesContext(_ context.Context, limit int) ([]ProcessInfo, error) {
+	return a.GetProcesses(limit)
+}
+
+func (a plainMonitorAdapter) GetNetworkUsageContext(_ context.Context) ([]NetInterfaceInfo, error) {
+	return a.GetNetworkUsage()
+}
+
+func (a plainMonitorAdapter) GetHostInfoContext(_ context.Context) (*HostInfo, error) {
+	return a.GetHostInfo()
+}
+
+// MemoryInfo holds clean memory statistics (wrapper around gopsutil data).
+// On Linux, Used excludes the page cache, which the kernel hands back on demand;
+// Available is the better measure of how much more memory programs can get.
 type MemoryInfo struct {
-	UsedPercent float64 // Memory percentage (0-100)
-	Used        uint64  // Memory used in bytes
-	Total       uint64  // Total memory in bytes
+	UsedPercent float64 `json:"used_percent"`    // Memory percentage (0-100)
+	Used        uint64  `json:"used_bytes"`      // Memory used in bytes
+	Total       uint64  `json:"total_bytes"`     // Total memory in bytes
+	Available   uint64  `json:"available_bytes"` // Memory programs can still get without swapping
+	Cached      uint64  `json:"cached_bytes"`    // Page cache - file contents kept in memory
+	Buffers     uint64  `json:"buffers_bytes"`   // Block device buffers
+	Shared      uint64  `json:"shared_bytes"`    // Shared memory and tmpfs
+	Dirty       uint64  `json:"dirty_bytes"`     // Changed pages not yet written to disk
+	Slab        uint64  `json:"slab_bytes"`      // Kernel data structure caches
+
+	SwapUsedPercent float64 `json:"swap_used_percent"` // Swap percentage (0-100), 0 without swap
+	SwapUsed        uint64  `json:"swap_used_bytes"`   // Swap used in bytes
+	SwapTotal       uint64  `json:"swap_total_bytes"`  // Total swap in bytes, 0 without swap
 }
 
 // DiskInfo holds clean disk statistics (wrapper around gopsutil data)
@@ -74,31 +189,129 @@ type DiskInfo struct {
 	Total       uint64  // Total disk space in bytes
 }
 
+// MountInfo holds disk statistics for one mounted filesystem
+type MountInfo struct {
+	Path        string  `json:"path"`         // Mount point, e.g. "/home"
+	Device      string  `json:"device"`       // Backing device, e.g. "/dev/sda2"
+	Fstype      string  `json:"fstype"`       // Filesystem type, e.g. "ext4"
+	UsedPercent float64 `json:"used_percent"` // Disk percentage (0-100)
+	Used        uint64  `json:"used_bytes"`   // Disk used in bytes
+	Total       uint64  `json:"total_bytes"`  // Total disk space in bytes
+}
+
+// MountFilter decides which mounted filesystems are reported.
+// Empty lists mean "no restriction" for include rules and "nothing excluded" for exclude rules.
+type MountFilter struct {
+	IncludeFSTypes []string // Only these filesystem types, if set
+	ExcludeFSTypes []string // Never these filesystem types (pseudo filesystems)
+	ExcludePaths   []string // Never mount points under these prefixes
+}
+
+// Allows reports whether a partition passes the filter rules.
+func (f MountFilter) Allows(p disk.PartitionStat) bool {
+	if len(f.IncludeFSTypes) > 0 && !containsFold(f.IncludeFSTypes, p.Fstype) {
+		return false
+	}
+	if containsFold(f.ExcludeFSTypes, p.Fstype) {
+		return false
+	}
+	for _, prefix := range f.ExcludePaths {
+		// Match whole path components so "/dev" doesn't exclude "/devel"
+		if p.Mountpoint == prefix || strings.HasPrefix(p.Mountpoint, strings.TrimSuffix(prefix, "/")+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// containsFold reports whether list contains value, ignoring case.
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
 // GopsutilMonitor is our production implementation of SystemMonitor.
 // It uses the gopsutil library to get real system metrics.
 // This is called a "concrete type" that implements the interface.
 type GopsutilMonitor struct {
 	// Dependencies for testing - these can be mocked
-	cpu  cpuProvider
-	mem  memProvider
-	disk diskProvider
+	cpu   cpuProvider
+	mem   memProvider
+	disk  diskProvider
+	procs procProvider
+	net   netProvider
+	host  hostProvider
+
+	// Previous samples, so CPU%, I/O and throughput can be computed as rates
+	procMu         sync.Mutex
+	procBaseline   processBaseline
+	netMu          sync.Mutex
+	netBaseline    netBaseline
+	diskIOMu       sync.Mutex
+	diskIOBaseline diskIOBaseline
+
+	// Host facts that rarely change, cached between reads
+	hostMu       sync.Mutex
+	hostIdentity *HostInfo
+	hostReadAt   time.Time
+}
+
+// Providers are the data sources of a GopsutilMonitor. Any left nil read
+// this machine, so a test only sets the ones it mocks.
+type Providers struct {
+	CPU   cpuProvider
+	Mem   memProvider
+	Disk  diskProvider
+	Net   netProvider
+	Procs procProvider
+	Host  hostProvider
 }
 
 // NewGopsutilMonitor creates a new monitor with injectable dependencies.
-// For production use, pass real providers. For testing, pass mocks.
-func NewGopsutilMonitor(cpuProv cpuProvider, memProv memProvider, diskProv diskProvider) SystemMonitor {
+// For production use, pass Providers{}. For testing, set the mocks.
+func NewGopsutilMonitor(p Providers) SystemMonitor {
+	if p.CPU == nil {
+		p.CPU = realCPUProvider{}
+	}
+	if p.Mem == nil {
+		p.Mem = realMemProvider{}
+	}
+	if p.Disk == nil {
+		p.Disk = realDiskProvider{}
+	}
+	if p.Net == nil {
+		p.Net = realNetProvider{}
+	}
+	if p.Procs == nil {
+		p.Procs = realProcProvider{}
+	}
+	if p.Host == nil {
+		p.Host = realHostProvider{}
+	}
 	return &GopsutilMonitor{
-		cpu:  cpuProv,
-		mem:  memProv,
-		disk: diskProv,
+		cpu:   p.CPU,
+		mem:   p.Mem,
+		disk:  p.Disk,
+		procs: p.Procs,
+		net:   p.Net,
+		host:  p.Host,
 	}
 }
 
 // GetCPUUsage implements SystemMonitor interface for CPU monitoring.
 // This wraps the gopsutil cpu.Percent function in our clean interface.
 func (g *GopsutilMonitor) GetCPUUsage(duration time.Duration) (float64, error) {
+	return g.GetCPUUsageContext(context.Background(), duration)
+}
+
+// GetCPUUsageContext implements ContextMonitor - the sample is cut short if ctx ends.
+func (g *GopsutilMonitor) GetCPUUsageContext(ctx context.Context, duration time.Duration) (float64, error) {
 	// Use injected dependency instead of calling cpu.Percent directly
-	percentages, err := g.cpu.Percent(duration, false)
+	percentages, err := g.cpu.PercentWithContext(ctx, duration, false)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get CPU usage: %w", err)
 	}
@@ -110,28 +323,71 @@ func (g *GopsutilMonitor) GetCPUUsage(duration time.Duration) (float64, error) {
 	return percentages[0], nil
 }
 
+// GetPerCoreUsage implements SystemMonitor interface for per-core CPU monitoring.
+// This is the same gopsutil call as GetCPUUsage with percpu enabled.
+func (g *GopsutilMonitor) GetPerCoreUsage(duration time.Duration) ([]float64, error) {
+	return g.GetPerCoreUsageContext(context.Background(), duration)
+}
+
+// GetPerCoreUsageContext implements ContextMonitor - the sample is cut short if ctx ends.
+func (g *GopsutilMonitor) GetPerCoreUsageContext(ctx context.Context, duration time.Duration) ([]float64, error) {
+	percentages, err := g.cpu.PercentWithContext(ctx, duration, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get per-core CPU usage: %w", err)
+	}
+
+	if len(percentages) == 0 {
+		return nil, fmt.Errorf("no per-core CPU usage data returned")
+	}
+
+	return percentages, nil
+}
+
 // GetMemoryUsage implements SystemMonitor interface for memory monitoring.
 // This wraps gopsutil mem.VirtualMemory in our clean interface.
 func (g *GopsutilMonitor) GetMemoryUsage() (*MemoryInfo, error) {
+	return g.GetMemoryUsageContext(context.Background())
+}
+
+// GetMemoryUsageContext implements ContextMonitor for memory monitoring.
+func (g *GopsutilMonitor) GetMemoryUsageContext(ctx context.Context) (*MemoryInfo, error) {
 	// Use injected dependency instead of calling mem.VirtualMemory directly
-	vmStat, err := g.mem.VirtualMemory()
+	vmStat, err := g.mem.VirtualMemoryWithContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get memory usage: %w", err)
 	}
+	swapStat, err := g.mem.SwapMemoryWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get swap usage: %w", err)
+	}
 
 	// Convert to our clean format
 	return &MemoryInfo{
-		UsedPercent: vmStat.UsedPercent,
-		Used:        vmStat.Used,
-		Total:       vmStat.Total,
+		UsedPercent:     vmStat.UsedPercent,
+		Used:            vmStat.Used,
+		Total:           vmStat.Total,
+		Available:       vmStat.Available,
+		Cached:          vmStat.Cached,
+		Buffers:         vmStat.Buffers,
+		Shared:          vmStat.Shared,
+		Dirty:           vmStat.Dirty,
+		Slab:            vmStat.Slab,
+		SwapUsedPercent: swapStat.UsedPercent,
+		SwapUsed:        swapStat.Used,
+		SwapTotal:       swapStat.Total,
 	}, nil
 }
 
 // GetDiskUsage implements SystemMonitor interface for disk monitoring.
 // This wraps gopsutil disk.Usage in our clean interface.
 func (g *GopsutilMonitor) GetDiskUsage(path string) (*DiskInfo, error) {
+	return g.GetDiskUsageContext(context.Background(), path)
+}
+
+// GetDiskUsageContext implements ContextMonitor for disk monitoring.
+func (g *GopsutilMonitor) GetDiskUsageContext(ctx context.Context, path string) (*DiskInfo, error) {
 	// Use injected dependency instead of calling disk.Usage directly
-	diskStat, err := g.disk.Usage(path)
+	diskStat, err := g.disk.UsageWithContext(ctx, path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get disk usage for %s: %w", path, err)
 	}
@@ -143,3 +399,50 @@ func (g *GopsutilMonitor) GetDiskUsage(path string) (*DiskInfo, error) {
 		Total:       diskStat.Total,
 	}, nil
 }
+
+// GetMountUsage implements SystemMonitor interface for per-mount disk monitoring.
+// This wraps gopsutil disk.Partitions and disk.Usage in our clean interface.
+func (g *GopsutilMonitor) GetMountUsage(filter MountFilter) ([]MountInfo, error) {
+	return g.GetMountUsageContext(context.Background(), filter)
+}
+
+// GetMountUsageContext implements ContextMonitor - remaining mounts are skipped once ctx ends.
+func (g *GopsutilMonitor) GetMountUsageContext(ctx context.Context, filter MountFilter) ([]MountInfo, error) {
+	// Only physical partitions - pseudo filesystems are filtered below as well
+	partitions, err := g.disk.PartitionsWithContext(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partitions: %w", err)
+	}
+
+	mounts := make([]MountInfo, 0, len(partitions))
+	seen := make(map[string]bool, len(partitions))
+	for _, p := range partitions {
+		// Stop early rather than querying every mount after a timeout
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// Bind mounts show the same mount point more than once
+		if seen[p.Mountpoint] || !filter.Allows(p) {
+			continue
+		}
+		seen[p.Mountpoint] = true
+
+		usage, err := g.disk.UsageWithContext(ctx, p.Mountpoint)
+		if err != nil {
+			// One unreadable mount (e.g. permission denied) shouldn't hide the rest
+			continue
+		}
+
+		mounts = append(mounts, MountInfo{
+			Path:        p.Mountpoint,
+			Device:      p.Device,
+			Fstype:      p.Fstype,
+			UsedPercent: usage.UsedPercent,
+			Used:        usage.Used,
+			Total:       usage.Total,
+		})
+	}
+
+	return mounts, nil
+}
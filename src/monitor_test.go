@@ -3,7 +3,9 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -19,35 +21,56 @@ type mockCPUProvider struct {
 	err         error
 }
 
-func (m mockCPUProvider) Percent(duration time.Duration, percpu bool) ([]float64, error) {
+func (m mockCPUProvider) PercentWithContext(ctx context.Context, duration time.Duration, percpu bool) ([]float64, error) {
 	return m.percentages, m.err
 }
 
 // mockMemProvider allows us to control memory function behavior in tests
 type mockMemProvider struct {
-	vmStat *mem.VirtualMemoryStat
-	err    error
+	vmStat   *mem.VirtualMemoryStat
+	err      error
+	swapStat *mem.SwapMemoryStat // nil means a machine without swap
+	swapErr  error
 }
 
-func (m mockMemProvider) VirtualMemory() (*mem.VirtualMemoryStat, error) {
+func (m mockMemProvider) VirtualMemoryWithContext(ctx context.Context) (*mem.VirtualMemoryStat, error) {
 	return m.vmStat, m.err
 }
 
+func (m mockMemProvider) SwapMemoryWithContext(ctx context.Context) (*mem.SwapMemoryStat, error) {
+	if m.swapStat == nil && m.swapErr == nil {
+		return &mem.SwapMemoryStat{}, nil
+	}
+	return m.swapStat, m.swapErr
+}
+
 // mockDiskProvider allows us to control disk function behavior in tests
 type mockDiskProvider struct {
-	usageStat *disk.UsageStat
-	err       error
+	usageStat     *disk.UsageStat
+	err           error
+	partitions    []disk.PartitionStat
+	partitionsErr error
+	ioCounters    map[string]disk.IOCountersStat
+	ioErr         error
 }
 
-func (m mockDiskProvider) Usage(path string) (*disk.UsageStat, error) {
+func (m mockDiskProvider) UsageWithContext(ctx context.Context, path string) (*disk.UsageStat, error) {
 	return m.usageStat, m.err
 }
 
+func (m mockDiskProvider) PartitionsWithContext(ctx context.Context, all bool) ([]disk.PartitionStat, error) {
+	return m.partitions, m.partitionsErr
+}
+
+func (m mockDiskProvider) IOCountersWithContext(ctx context.Context, names ...string) (map[string]disk.IOCountersStat, error) {
+	return m.ioCounters, m.ioErr
+}
+
 // TestNewGopsutilMonitor tests the constructor function.
 // This tests that we get a valid monitor instance.
 func TestNewGopsutilMonitor(t *testing.T) {
 	// Act
-	monitor := NewGopsutilMonitor(realCPUProvider{}, realMemProvider{}, realDiskProvider{})
+	monitor := NewGopsutilMonitor(Providers{})
 
 	// Assert
 	if monitor == nil {
@@ -58,6 +81,13 @@ func TestNewGopsutilMonitor(t *testing.T) {
 	if monitor == nil {
 		t.Error("NewGopsutilMonitor returned nil")
 	}
+
+	// Mocks are kept, unset providers read this machine
+	mockDisk := &mockDiskProvider{}
+	g := NewGopsutilMonitor(Providers{Disk: mockDisk}).(*GopsutilMonitor)
+	if g.disk != mockDisk || g.cpu != (realCPUProvider{}) || g.host != (realHostProvider{}) {
+		t.Errorf("Expected the mock disk and real defaults, got %+v", g)
+	}
 }
 
 // TestGopsutilMonitorImplementsInterface tests interface compliance.
@@ -65,6 +95,8 @@ func TestNewGopsutilMonitor(t *testing.T) {
 func TestGopsutilMonitorImplementsInterface(t *testing.T) {
 	// Compile-time check that GopsutilMonitor implements SystemMonitor
 	var _ SystemMonitor = &GopsutilMonitor{}
+	// ...and the context-aware variants used by the collectors
+	var _ ContextMonitor = &GopsutilMonitor{}
 
 	// If this compiles, the test passes!
 	t.Log("GopsutilMonitor correctly implements SystemMonitor interface")
@@ -117,7 +149,7 @@ func TestDiskInfoStruct(t *testing.T) {
 // This tests our actual production code that calls gopsutil.
 func TestGopsutilMonitorCPUUsage(t *testing.T) {
 	// Arrange
-	monitor := NewGopsutilMonitor(realCPUProvider{}, realMemProvider{}, realDiskProvider{})
+	monitor := NewGopsutilMonitor(Providers{})
 
 	// Act
 	cpu, err := monitor.GetCPUUsage(100 * time.Millisecond)
@@ -138,7 +170,7 @@ func TestGopsutilMonitorCPUUsage(t *testing.T) {
 // This tests our actual production code that calls gopsutil.
 func TestGopsutilMonitorMemoryUsage(t *testing.T) {
 	// Arrange
-	monitor := NewGopsutilMonitor(realCPUProvider{}, realMemProvider{}, realDiskProvider{})
+	monitor := NewGopsutilMonitor(Providers{})
 
 	// Act
 	mem, err := monitor.GetMemoryUsage()
@@ -174,7 +206,7 @@ func TestGopsutilMonitorMemoryUsage(t *testing.T) {
 // This tests our actual production code that calls gopsutil.
 func TestGopsutilMonitorDiskUsage(t *testing.T) {
 	// Arrange
-	monitor := NewGopsutilMonitor(realCPUProvider{}, realMemProvider{}, realDiskProvider{})
+	monitor := NewGopsutilMonitor(Providers{})
 
 	// Act - Test with a path that should exist on most systems
 	disk, err := monitor.GetDiskUsage("C:")
@@ -214,7 +246,7 @@ func TestGopsutilMonitorDiskUsage(t *testing.T) {
 // This tests how our real code handles invalid inputs.
 func TestGopsutilMonitorErrorHandling(t *testing.T) {
 	// Arrange
-	monitor := NewGopsutilMonitor(realCPUProvider{}, realMemProvider{}, realDiskProvider{})
+	monitor := NewGopsutilMonitor(Providers{})
 
 	// Test invalid disk path
 	_, err := monitor.GetDiskUsage("/this/path/definitely/does/not/exist/on/any/system")
@@ -232,7 +264,7 @@ func TestGopsutilMonitorErrorHandling(t *testing.T) {
 // This ensures our MemoryInfo and DiskInfo structs contain the expected data.
 func TestGopsutilMonitorDataConsistency(t *testing.T) {
 	// Arrange
-	monitor := NewGopsutilMonitor(realCPUProvider{}, realMemProvider{}, realDiskProvider{})
+	monitor := NewGopsutilMonitor(Providers{})
 
 	// Act
 	mem, err := monitor.GetMemoryUsage()
@@ -260,7 +292,7 @@ func TestGopsutilMonitorErrorPaths(t *testing.T) {
 			percentages: nil,
 			err:         errors.New("mock CPU error"),
 		}
-		monitor := NewGopsutilMonitor(mockCPU, realMemProvider{}, realDiskProvider{})
+		monitor := NewGopsutilMonitor(Providers{CPU: mockCPU})
 
 		// Act
 		_, err := monitor.GetCPUUsage(100 * time.Millisecond)
@@ -280,7 +312,7 @@ func TestGopsutilMonitorErrorPaths(t *testing.T) {
 			percentages: []float64{}, // Empty slice
 			err:         nil,
 		}
-		monitor := NewGopsutilMonitor(mockCPU, realMemProvider{}, realDiskProvider{})
+		monitor := NewGopsutilMonitor(Providers{CPU: mockCPU})
 
 		// Act
 		_, err := monitor.GetCPUUsage(100 * time.Millisecond)
@@ -294,13 +326,41 @@ func TestGopsutilMonitorErrorPaths(t *testing.T) {
 		}
 	})
 
+	t.Run("Per-Core Percent Error", func(t *testing.T) {
+		// Arrange
+		mockCPU := mockCPUProvider{err: errors.New("mock CPU error")}
+		monitor := NewGopsutilMonitor(Providers{CPU: mockCPU})
+
+		// Act
+		_, err := monitor.GetPerCoreUsage(100 * time.Millisecond)
+
+		// Assert
+		if err == nil || !contains(err.Error(), "failed to get per-core CPU usage") {
+			t.Errorf("Expected per-core CPU error, got %v", err)
+		}
+	})
+
+	t.Run("Per-Core Empty Slice", func(t *testing.T) {
+		// Arrange
+		mockCPU := mockCPUProvider{percentages: []float64{}}
+		monitor := NewGopsutilMonitor(Providers{CPU: mockCPU})
+
+		// Act
+		_, err := monitor.GetPerCoreUsage(100 * time.Millisecond)
+
+		// Assert
+		if err == nil || !contains(err.Error(), "no per-core CPU usage data returned") {
+			t.Errorf("Expected empty per-core error, got %v", err)
+		}
+	})
+
 	t.Run("Memory VirtualMemory Error", func(t *testing.T) {
 		// Arrange - Simple dependency injection
 		mockMem := mockMemProvider{
 			vmStat: nil,
 			err:    errors.New("mock memory error"),
 		}
-		monitor := NewGopsutilMonitor(realCPUProvider{}, mockMem, realDiskProvider{})
+		monitor := NewGopsutilMonitor(Providers{Mem: mockMem})
 
 		// Act
 		_, err := monitor.GetMemoryUsage()
@@ -320,7 +380,7 @@ func TestGopsutilMonitorErrorPaths(t *testing.T) {
 			usageStat: nil,
 			err:       errors.New("mock disk error"),
 		}
-		monitor := NewGopsutilMonitor(realCPUProvider{}, realMemProvider{}, mockDisk)
+		monitor := NewGopsutilMonitor(Providers{Disk: mockDisk})
 
 		// Act
 		_, err := monitor.GetDiskUsage("/invalid/path")
@@ -344,7 +404,7 @@ func TestGopsutilMonitorSuccessPaths(t *testing.T) {
 			percentages: []float64{45.5}, // Valid CPU percentage
 			err:         nil,
 		}
-		monitor := NewGopsutilMonitor(mockCPU, realMemProvider{}, realDiskProvider{})
+		monitor := NewGopsutilMonitor(Providers{CPU: mockCPU})
 
 		// Act
 		cpu, err := monitor.GetCPUUsage(100 * time.Millisecond)
@@ -358,6 +418,26 @@ func TestGopsutilMonitorSuccessPaths(t *testing.T) {
 		}
 	})
 
+	t.Run("Per-Core Success", func(t *testing.T) {
+		// Arrange - one pinned core among idle ones
+		mockCPU := mockCPUProvider{percentages: []float64{2.0, 100.0, 3.5, 1.0}}
+		monitor := NewGopsutilMonitor(Providers{CPU: mockCPU})
+
+		// Act
+		cores, err := monitor.GetPerCoreUsage(100 * time.Millisecond)
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(cores) != 4 {
+			t.Fatalf("Expected 4 cores, got %d", len(cores))
+		}
+		if cores[1] != 100.0 {
+			t.Errorf("Expected core 1 at 100%%, got %f%%", cores[1])
+		}
+	})
+
 	t.Run("Memory Success", func(t *testing.T) {
 		// Arrange - Simple dependency injection
 		mockMem := mockMemProvider{
@@ -368,7 +448,7 @@ func TestGopsutilMonitorSuccessPaths(t *testing.T) {
 			},
 			err: nil,
 		}
-		monitor := NewGopsutilMonitor(realCPUProvider{}, mockMem, realDiskProvider{})
+		monitor := NewGopsutilMonitor(Providers{Mem: mockMem})
 
 		// Act
 		mem, err := monitor.GetMemoryUsage()
@@ -385,6 +465,47 @@ func TestGopsutilMonitorSuccessPaths(t *testing.T) {
 		}
 	})
 
+	t.Run("Memory Breakdown And Swap", func(t *testing.T) {
+		// Arrange
+		mockMem := mockMemProvider{
+			vmStat: &mem.VirtualMemoryStat{
+				Total: 1000, Used: 300, Available: 600,
+				Cached: 250, Buffers: 50, Shared: 20, Dirty: 5, Slab: 40,
+			},
+			swapStat: &mem.SwapMemoryStat{Total: 400, Used: 100, UsedPercent: 25},
+		}
+		monitor := NewGopsutilMonitor(Providers{Mem: mockMem})
+
+		// Act
+		mem, err := monitor.GetMemoryUsage()
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := MemoryInfo{
+			Total: 1000, Used: 300, Available: 600,
+			Cached: 250, Buffers: 50, Shared: 20, Dirty: 5, Slab: 40,
+			SwapUsedPercent: 25, SwapUsed: 100, SwapTotal: 400,
+		}
+		if *mem != want {
+			t.Errorf("Expected %+v, got %+v", want, *mem)
+		}
+	})
+
+	t.Run("Memory Swap Error", func(t *testing.T) {
+		mockMem := mockMemProvider{
+			vmStat:  &mem.VirtualMemoryStat{Total: 1000},
+			swapErr: errors.New("mock swap error"),
+		}
+		monitor := NewGopsutilMonitor(Providers{Mem: mockMem})
+
+		_, err := monitor.GetMemoryUsage()
+		if err == nil || !contains(err.Error(), "failed to get swap usage") {
+			t.Errorf("Expected swap error, got %v", err)
+		}
+	})
+
 	t.Run("Disk Success", func(t *testing.T) {
 		// Arrange - Simple dependency injection
 		mockDisk := mockDiskProvider{
@@ -395,7 +516,7 @@ func TestGopsutilMonitorSuccessPaths(t *testing.T) {
 			},
 			err: nil,
 		}
-		monitor := NewGopsutilMonitor(realCPUProvider{}, realMemProvider{}, mockDisk)
+		monitor := NewGopsutilMonitor(Providers{Disk: mockDisk})
 
 		// Act
 		disk, err := monitor.GetDiskUsage("/test")
@@ -413,6 +534,76 @@ func TestGopsutilMonitorSuccessPaths(t *testing.T) {
 	})
 }
 
+// TestGopsutilMonitorMountUsage tests mount enumeration and filtering with mocked partitions.
+func TestGopsutilMonitorMountUsage(t *testing.T) {
+	// Arrange - a mix of real and pseudo filesystems, plus a duplicate bind mount
+	mockDisk := mockDiskProvider{
+		usageStat: &disk.UsageStat{UsedPercent: 50.0, Used: 50, Total: 100},
+		partitions: []disk.PartitionStat{
+			{Device: "/dev/sda1", Mountpoint: "/", Fstype: "ext4"},
+			{Device: "/dev/sda2", Mountpoint: "/home", Fstype: "ext4"},
+			{Device: "/dev/sda2", Mountpoint: "/home", Fstype: "ext4"},
+			{Device: "tmpfs", Mountpoint: "/run", Fstype: "tmpfs"},
+			{Device: "proc", Mountpoint: "/proc", Fstype: "proc"},
+			{Device: "/dev/loop0", Mountpoint: "/devel", Fstype: "xfs"},
+			{Device: "/dev/loop1", Mountpoint: "/dev/shm2", Fstype: "xfs"},
+		},
+	}
+	monitor := NewGopsutilMonitor(Providers{Disk: mockDisk})
+	filter := MountFilter{
+		ExcludeFSTypes: []string{"tmpfs", "proc"},
+		ExcludePaths:   []string{"/dev"},
+	}
+
+	t.Run("Exclude Rules", func(t *testing.T) {
+		// Act
+		mounts, err := monitor.GetMountUsage(filter)
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var paths []string
+		for _, m := range mounts {
+			paths = append(paths, m.Path)
+		}
+		expected := "/,/home,/devel"
+		if got := strings.Join(paths, ","); got != expected {
+			t.Errorf("Expected mounts %s, got %s", expected, got)
+		}
+		if mounts[0].Device != "/dev/sda1" || mounts[0].UsedPercent != 50.0 {
+			t.Errorf("Unexpected mount details: %+v", mounts[0])
+		}
+	})
+
+	t.Run("Include Rules", func(t *testing.T) {
+		// Act
+		mounts, err := monitor.GetMountUsage(MountFilter{IncludeFSTypes: []string{"XFS"}})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(mounts) != 2 {
+			t.Errorf("Expected 2 xfs mounts, got %d: %+v", len(mounts), mounts)
+		}
+	})
+
+	t.Run("Partitions Error", func(t *testing.T) {
+		// Arrange
+		failing := mockDiskProvider{partitionsErr: errors.New("mock partitions error")}
+		monitor := NewGopsutilMonitor(Providers{Disk: failing})
+
+		// Act
+		_, err := monitor.GetMountUsage(filter)
+
+		// Assert
+		if err == nil || !contains(err.Error(), "failed to list partitions") {
+			t.Errorf("Expected partitions error, got %v", err)
+		}
+	})
+}
+
 // Helper functions for tests
 func contains(s, substr string) bool {
 	return len(substr) <= len(s) && (substr == s ||
@@ -441,7 +632,7 @@ func abs(x float64) float64 {
 // This ensures GopsutilMonitor actually implements SystemMonitor correctly.
 func TestSystemMonitorInterface(t *testing.T) {
 	// Arrange - Create real monitor
-	monitor := NewGopsutilMonitor(realCPUProvider{}, realMemProvider{}, realDiskProvider{})
+	monitor := NewGopsutilMonitor(Providers{})
 
 	// Act & Assert - Verify it's not nil
 	if monitor == nil {
@@ -478,7 +669,7 @@ func TestValidMemoryPercentages(t *testing.T) {
 	}
 
 	// Arrange
-	monitor := NewGopsutilMonitor(realCPUProvider{}, realMemProvider{}, realDiskProvider{})
+	monitor := NewGopsutilMonitor(Providers{})
 
 	// Act
 	mem, err := monitor.GetMemoryUsage()
@@ -0,0 +1,104 @@
+// Package main provides network monitoring for the hardware monitor.
+// This file contains the network provider and the per-interface throughput
+// calculation, which turns the OS's cumulative counters into per-second rates.
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/net"
+)
+
+// netProvider wraps gopsutil network functions
+type netProvider interface {
+	IOCountersWithContext(ctx context.Context, pernic bool) ([]net.IOCountersStat, error)
+}
+
+// realNetProvider is the production netProvider.
+type realNetProvider struct{}
+
+func (r realNetProvider) IOCountersWithContext(ctx context.Context, pernic bool) ([]net.IOCountersStat, error) {
+	return net.IOCountersWithContext(ctx, pernic)
+}
+
+// NetInterfaceInfo holds the throughput of one network interface since the
+// previous collection. Errors and drops are counts over that period.
+type NetInterfaceInfo struct {
+	Name            string  `json:"name"`
+	RxBytesPerSec   float64 `json:"rx_bytes_per_second"`
+	TxBytesPerSec   float64 `json:"tx_bytes_per_second"`
+	RxPacketsPerSec float64 `json:"rx_packets_per_second"`
+	TxPacketsPerSec float64 `json:"tx_packets_per_second"`
+	RxErrors        uint64  `json:"rx_errors"`
+	TxErrors        uint64  `json:"tx_errors"`
+	RxDrops         uint64  `json:"rx_drops"`
+	TxDrops         uint64  `json:"tx_drops"`
+}
+
+// netBaseline is the previous counter reading of every interface.
+type netBaseline struct {
+	at       time.Time
+	counters map[string]net.IOCountersStat
+}
+
+// GetNetworkUsage implements SystemMonitor interface for network monitoring.
+// This wraps gopsutil net.IOCounters in our clean interface.
+func (g *GopsutilMonitor) GetNetworkUsage() ([]NetInterfaceInfo, error) {
+	return g.GetNetworkUsageContext(context.Background())
+}
+
+// GetNetworkUsageContext implements ContextMonitor for network monitoring.
+// Rates cover the time since the previous call, so the very first call only
+// takes the baseline and returns ErrNoBaseline.
+func (g *GopsutilMonitor) GetNetworkUsageContext(ctx context.Context) ([]NetInterfaceInfo, error) {
+	counters, err := g.net.IOCountersWithContext(ctx, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network counters: %w", err)
+	}
+	now := time.Now()
+
+	// Swap in the new baseline; a timed-out call may still be finishing
+	g.netMu.Lock()
+	previous := g.netBaseline
+	g.netBaseline = netBaseline{at: now, counters: make(map[string]net.IOCountersStat, len(counters))}
+	for _, c := range counters {
+		g.netBaseline.counters[c.Name] = c
+	}
+	g.netMu.Unlock()
+	if previous.counters == nil {
+		return nil, ErrNoBaseline
+	}
+
+	elapsed := now.Sub(previous.at).Seconds()
+	interfaces := make([]NetInterfaceInfo, 0, len(counters))
+	for _, c := range counters {
+		if containsFold(config.NetExcludeInterfaces, c.Name) {
+			continue
+		}
+		info := NetInterfaceInfo{Name: c.Name}
+		if prev, ok := previous.counters[c.Name]; ok && elapsed > 0 {
+			info.RxBytesPerSec = float64(counterDelta(prev.BytesRecv, c.BytesRecv)) / elapsed
+			info.TxBytesPerSec = float64(counterDelta(prev.BytesSent, c.BytesSent)) / elapsed
+			info.RxPacketsPerSec = float64(counterDelta(prev.PacketsRecv, c.PacketsRecv)) / elapsed
+			info.TxPacketsPerSec = float64(counterDelta(prev.PacketsSent, c.PacketsSent)) / elapsed
+			info.RxErrors = counterDelta(prev.Errin, c.Errin)
+			info.TxErrors = counterDelta(prev.Errout, c.Errout)
+			info.RxDrops = counterDelta(prev.Dropin, c.Dropin)
+			info.TxDrops = counterDelta(prev.Dropout, c.Dropout)
+		}
+		interfaces = append(interfaces, info)
+	}
+	return interfaces, nil
+}
+
+// counterDelta returns how much a cumulative counter grew.
+// A counter that went backwards was reset (e.g. the interface was
+// re-created), so there is no meaningful delta and 0 is returned.
+func counterDelta(previous, current uint64) uint64 {
+	if current < previous {
+		return 0
+	}
+	return current - previous
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/net"
+)
+
+// mockNetProvider allows us to control network counters in tests
+type mockNetProvider struct {
+	counters []net.IOCountersStat
+	err      error
+}
+
+func (m mockNetProvider) IOCountersWithContext(ctx context.Context, pernic bool) ([]net.IOCountersStat, error) {
+	return m.counters, m.err
+}
+
+func TestGopsutilMonitorNetworkUsage(t *testing.T) {
+	t.Run("FirstCall", func(t *testing.T) {
+		monitor := NewGopsutilMonitor(Providers{Net: mockNetProvider{counters: []net.IOCountersStat{
+			{Name: "eth0", BytesRecv: 5000},
+		}}})
+
+		interfaces, err := monitor.GetNetworkUsage()
+
+		// No baseline yet - reported as pending rather than as an idle network
+		if !errors.Is(err, ErrNoBaseline) || interfaces != nil {
+			t.Errorf("Expected ErrNoBaseline, got %+v and %v", interfaces, err)
+		}
+		if _, err := monitor.GetNetworkUsage(); err != nil {
+			t.Errorf("Expected the second call to have a baseline, got %v", err)
+		}
+	})
+
+	t.Run("Rates", func(t *testing.T) {
+		// Arrange - a baseline 2 seconds ago; eth1 was reset since
+		monitor := NewGopsutilMonitor(Providers{Net: mockNetProvider{counters: []net.IOCountersStat{
+			{Name: "lo", BytesRecv: 1 << 30},
+			{Name: "eth0", BytesRecv: 6000, BytesSent: 3000, PacketsRecv: 40, PacketsSent: 20, Errin: 3, Dropout: 1},
+			{Name: "eth1", BytesRecv: 10},
+		}}}).(*GopsutilMonitor)
+		monitor.netBaseline = netBaseline{
+			at: time.Now().Add(-2 * time.Second),
+			counters: map[string]net.IOCountersStat{
+				"lo":   {Name: "lo"},
+				"eth0": {Name: "eth0", BytesRecv: 2000, BytesSent: 1000, PacketsRecv: 20, PacketsSent: 10, Errin: 1},
+				"eth1": {Name: "eth1", BytesRecv: 9999},
+			},
+		}
+
+		// Act
+		interfaces, err := monitor.GetNetworkUsage()
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(interfaces) != 2 {
+			t.Fatalf("Expected loopback to be excluded, got %+v", interfaces)
+		}
+		eth0 := interfaces[0]
+		if math.Abs(eth0.RxBytesPerSec-2000) > 50 || math.Abs(eth0.TxBytesPerSec-1000) > 25 {
+			t.Errorf("Expected about 2000 B/s in and 1000 B/s out, got %+v", eth0)
+		}
+		if math.Abs(eth0.RxPacketsPerSec-10) > 1 || math.Abs(eth0.TxPacketsPerSec-5) > 1 {
+			t.Errorf("Expected about 10 pkt/s in and 5 pkt/s out, got %+v", eth0)
+		}
+		if eth0.RxErrors != 2 || eth0.TxDrops != 1 {
+			t.Errorf("Expected 2 receive errors and 1 transmit drop, got %+v", eth0)
+		}
+		if interfaces[1].RxBytesPerSec != 0 {
+			t.Errorf("Expected no rate for a reset counter, got %f", interfaces[1].RxBytesPerSec)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		monitor := NewGopsutilMonitor(Providers{Net: mockNetProvider{err: errors.New("network error")}})
+
+		if _, err := monitor.GetNetworkUsage(); err == nil {
+			t.Error("Expected error, got nil")
+		}
+	})
+}
+
+func TestRealNetProvider(t *testing.T) {
+	// Every supported OS can report interface counters
+	if _, err := (realNetProvider{}).IOCountersWithContext(context.Background(), true); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
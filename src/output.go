@@ -0,0 +1,22 @@
+// Package main provides headless output for the hardware monitor.
+// This file contains the JSON Lines writer used by --output=jsonl, which lets
+// the monitor feed jq, log shippers, cron jobs and plain SSH sessions.
+package main
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Output modes accepted by --output.
+const (
+	outputTUI   = "tui"   // Interactive terminal dashboard
+	outputJSONL = "jsonl" // One JSON object per snapshot on stdout
+)
+
+// writeJSONLine writes one snapshot as a single line of JSON.
+// Failed collectors appear in the "status" object with their error message.
+func writeJSONLine(w io.Writer, stats SystemStats) error {
+	// Encode appends the newline that terminates each JSON Lines record
+	return json.NewEncoder(w).Encode(stats)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteJSONLine(t *testing.T) {
+	// Arrange - one healthy and one failed collector
+	var stats SystemStats
+	stats.Time = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	stats.CPUUsage = 12.5
+	stats.setStatus("cpu", nil)
+	stats.setStatus("disk", errors.New("disk error"))
+
+	var buf bytes.Buffer
+
+	// Act
+	err := writeJSONLine(&buf, stats)
+
+	// Assert - exactly one line of valid JSON
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	line := buf.String()
+	if strings.Count(line, "\n") != 1 || !strings.HasSuffix(line, "\n") {
+		t.Fatalf("Expected a single newline-terminated line, got %q", line)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &decoded); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if decoded["time"] != "2024-01-02T03:04:05Z" {
+		t.Errorf("Expected RFC 3339 timestamp, got %v", decoded["time"])
+	}
+	if decoded["cpu_usage"] != 12.5 {
+		t.Errorf("Expected cpu_usage 12.5, got %v", decoded["cpu_usage"])
+	}
+
+	status := decoded["status"].(map[string]interface{})
+	disk := status["disk"].(map[string]interface{})
+	if disk["state"] != "error" || disk["error"] != "disk error" {
+		t.Errorf("Expected disk error in status, got %v", disk)
+	}
+	if _, ok := status["cpu"].(map[string]interface{})["error"]; ok {
+		t.Error("Expected no error field for a healthy collector")
+	}
+}
@@ -0,0 +1,240 @@
+// Package main provides process monitoring for the hardware monitor.
+// This file contains the process provider, the per-process rate calculation,
+// and the selection and sorting of the top processes.
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// procProvider wraps gopsutil process functions
+type procProvider interface {
+	ProcessesWithContext(ctx context.Context) ([]ProcessSample, error)
+}
+
+// ProcessSample is the raw state of one process as read from the OS.
+// CPU time and I/O are cumulative since the process started; GopsutilMonitor
+// turns them into rates by comparing two samples.
+type ProcessSample struct {
+	PID        int32
+	User       string
+	Command    string
+	State      string
+	CPUSeconds float64 // User + system CPU time
+	RSS        uint64  // Resident memory in bytes
+	IOBytes    uint64  // Bytes read + written
+}
+
+// ProcessInfo describes one process in a snapshot.
+type ProcessInfo struct {
+	PID        int32   `json:"pid"`
+	User       string  `json:"user"`
+	Command    string  `json:"command"`
+	State      string  `json:"state"`
+	CPUPercent float64 `json:"cpu_percent"` // Of one core, so it can exceed 100 like in top
+	RSS        uint64  `json:"rss_bytes"`   // Resident memory in bytes
+	IORate     float64 `json:"io_bytes_per_second"`
+}
+
+// realProcProvider reads every process through gopsutil.
+type realProcProvider struct{}
+
+func (r realProcProvider) ProcessesWithContext(ctx context.Context) ([]ProcessSample, error) {
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]ProcessSample, 0, len(procs))
+	for _, p := range procs {
+		// Reading every process takes a while - give up once the collector times out
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// Processes may exit while we read them; skip any we can't time
+		times, err := p.TimesWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		sample := ProcessSample{
+			PID:        p.Pid,
+			User:       processUser(ctx, p),
+			Command:    processCommand(ctx, p),
+			CPUSeconds: times.User + times.System,
+		}
+
+		// The rest is best effort: other users' I/O usually needs root
+		if states, err := p.StatusWithContext(ctx); err == nil && len(states) > 0 {
+			sample.State = states[0]
+		}
+		if memInfo, err := p.MemoryInfoWithContext(ctx); err == nil {
+			sample.RSS = memInfo.RSS
+		}
+		if io, err := p.IOCountersWithContext(ctx); err == nil {
+			sample.IOBytes = io.ReadBytes + io.WriteBytes
+		}
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
+
+// processUser returns the owner's name, or the numeric uid if it has no name
+// (common in containers).
+func processUser(ctx context.Context, p *process.Process) string {
+	if user, err := p.UsernameWithContext(ctx); err == nil && user != "" {
+		return user
+	}
+	if uids, err := p.UidsWithContext(ctx); err == nil && len(uids) > 0 {
+		return strconv.Itoa(int(uids[0]))
+	}
+	return "?"
+}
+
+// processCommand returns the command line, or just the name for processes
+// without one, such as kernel threads.
+func processCommand(ctx context.Context, p *process.Process) string {
+	if cmdline, err := p.CmdlineWithContext(ctx); err == nil && cmdline != "" {
+		return strings.Join(strings.Fields(cmdline), " ") // No tabs or newlines in a table cell
+	}
+	if name, err := p.NameWithContext(ctx); err == nil {
+		return name
+	}
+	return "?"
+}
+
+// processBaseline is the previous sample of every process, used for rates.
+type processBaseline struct {
+	at      time.Time
+	samples map[int32]ProcessSample
+}
+
+// GetProcesses implements SystemMonitor interface for process monitoring.
+// This wraps gopsutil process listing in our clean interface.
+func (g *GopsutilMonitor) GetProcesses(limit int) ([]ProcessInfo, error) {
+	return g.GetProcessesContext(context.Background(), limit)
+}
+
+// GetProcessesContext implements ContextMonitor for process monitoring.
+// CPU% and I/O rates cover the time since the previous call, so the very
+// first call reports them as 0.
+func (g *GopsutilMonitor) GetProcessesContext(ctx context.Context, limit int) ([]ProcessInfo, error) {
+	samples, err := g.procs.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+	now := time.Now()
+
+	// Swap in the new baseline; a timed-out call may still be finishing
+	g.procMu.Lock()
+	previous := g.procBaseline
+	g.procBaseline = processBaseline{at: now, samples: make(map[int32]ProcessSample, len(samples))}
+	for _, s := range samples {
+		g.procBaseline.samples[s.PID] = s
+	}
+	g.procMu.Unlock()
+
+	elapsed := now.Sub(previous.at).Seconds()
+	infos := make([]ProcessInfo, 0, len(samples))
+	for _, s := range samples {
+		info := ProcessInfo{
+			PID:     s.PID,
+			User:    s.User,
+			Command: s.Command,
+			State:   s.State,
+			RSS:     s.RSS,
+		}
+		// Counters going backwards mean the PID was reused by a new process
+		if prev, ok := previous.samples[s.PID]; ok && elapsed > 0 && s.CPUSeconds >= prev.CPUSeconds {
+			info.CPUPercent = (s.CPUSeconds - prev.CPUSeconds) / elapsed * 100
+			if s.IOBytes >= prev.IOBytes {
+				info.IORate = float64(s.IOBytes-prev.IOBytes) / elapsed
+			}
+		}
+		infos = append(infos, info)
+	}
+
+	return topProcesses(infos, limit), nil
+}
+
+// processSort names a column the process table can be sorted by.
+type processSort int
+
+const (
+	sortByCPU processSort = iota
+	sortByMemory
+	sortByIO
+	sortByPID
+	processSortCount // Number of sort columns, for cycling
+)
+
+// String returns the column name shown in the UI.
+func (s processSort) String() string {
+	switch s {
+	case sortByMemory:
+		return "Memory"
+	case sortByIO:
+		return "I/O"
+	case sortByPID:
+		return "PID"
+	default:
+		return "CPU"
+	}
+}
+
+// sortProcesses orders procs in place: busiest first, or by ascending PID.
+// Ties are broken by PID so rows don't jump around between refreshes.
+func sortProcesses(procs []ProcessInfo, by processSort) {
+	sort.SliceStable(procs, func(i, j int) bool {
+		a, b := procs[i], procs[j]
+		switch by {
+		case sortByMemory:
+			if a.RSS != b.RSS {
+				return a.RSS > b.RSS
+			}
+		case sortByIO:
+			if a.IORate != b.IORate {
+				return a.IORate > b.IORate
+			}
+		case sortByCPU:
+			if a.CPUPercent != b.CPUPercent {
+				return a.CPUPercent > b.CPUPercent
+			}
+		}
+		return a.PID < b.PID
+	})
+}
+
+// topProcesses keeps the top limit processes by CPU, by memory and by I/O,
+// so the table can be re-sorted by any of them without collecting again.
+// The result is sorted by CPU.
+func topProcesses(procs []ProcessInfo, limit int) []ProcessInfo {
+	if limit <= 0 || len(procs) <= limit {
+		sortProcesses(procs, sortByCPU)
+		return procs
+	}
+
+	keep := make(map[int32]bool, 3*limit)
+	for _, by := range []processSort{sortByCPU, sortByMemory, sortByIO} {
+		sortProcesses(procs, by)
+		for _, p := range procs[:limit] {
+			keep[p.PID] = true
+		}
+	}
+
+	top := make([]ProcessInfo, 0, len(keep))
+	for _, p := range procs {
+		if keep[p.PID] {
+			top = append(top, p)
+		}
+	}
+	sortProcesses(top, sortByCPU)
+	return top
+}
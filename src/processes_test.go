@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+// mockProcProvider returns a fixed process list
+type mockProcProvider struct {
+	samples []ProcessSample
+	err     error
+}
+
+func (m mockProcProvider) ProcessesWithContext(ctx context.Context) ([]ProcessSample, error) {
+	return m.samples, m.err
+}
+
+func TestGopsutilMonitorProcesses(t *testing.T) {
+	t.Run("Rates", func(t *testing.T) {
+		// Arrange - a baseline taken 2 seconds ago
+		monitor := NewGopsutilMonitor(Providers{Procs: mockProcProvider{samples: []ProcessSample{
+			{PID: 1, User: "root", Command: "init", State: "sleep", CPUSeconds: 11, RSS: 100, IOBytes: 4000},
+			{PID: 2, User: "app", Command: "new", CPUSeconds: 5},
+		}}}).(*GopsutilMonitor)
+		monitor.procBaseline = processBaseline{
+			at:      time.Now().Add(-2 * time.Second),
+			samples: map[int32]ProcessSample{1: {PID: 1, CPUSeconds: 10, IOBytes: 2000}},
+		}
+
+		// Act
+		procs, err := monitor.GetProcesses(10)
+
+		// Assert - 1 CPU second and 2000 bytes over 2 seconds
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(procs) != 2 || procs[0].PID != 1 {
+			t.Fatalf("Expected PID 1 first (busiest), got %+v", procs)
+		}
+		if math.Abs(procs[0].CPUPercent-50) > 1 {
+			t.Errorf("Expected about 50%% CPU, got %f", procs[0].CPUPercent)
+		}
+		if math.Abs(procs[0].IORate-1000) > 20 {
+			t.Errorf("Expected about 1000 B/s I/O, got %f", procs[0].IORate)
+		}
+		if procs[0].User != "root" || procs[0].Command != "init" || procs[0].State != "sleep" || procs[0].RSS != 100 {
+			t.Errorf("Expected process details to be copied, got %+v", procs[0])
+		}
+		// A process without a baseline has no rate yet
+		if procs[1].CPUPercent != 0 {
+			t.Errorf("Expected 0%% CPU for a new process, got %f", procs[1].CPUPercent)
+		}
+	})
+
+	t.Run("ReusedPID", func(t *testing.T) {
+		monitor := NewGopsutilMonitor(Providers{Procs: mockProcProvider{samples: []ProcessSample{{PID: 7, CPUSeconds: 1}}}}).(*GopsutilMonitor)
+		monitor.procBaseline = processBaseline{
+			at:      time.Now().Add(-time.Second),
+			samples: map[int32]ProcessSample{7: {PID: 7, CPUSeconds: 500}},
+		}
+
+		procs, err := monitor.GetProcesses(10)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if procs[0].CPUPercent != 0 {
+			t.Errorf("Expected no rate when the counter went backwards, got %f", procs[0].CPUPercent)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		monitor := NewGopsutilMonitor(Providers{Procs: mockProcProvider{err: errors.New("no /proc")}})
+
+		if _, err := monitor.GetProcesses(10); err == nil {
+			t.Error("Expected error, got nil")
+		}
+	})
+}
+
+func TestTopProcesses(t *testing.T) {
+	// Arrange - each process leads in exactly one column
+	procs := []ProcessInfo{
+		{PID: 1, CPUPercent: 90},
+		{PID: 2, RSS: 1 << 30},
+		{PID: 3, IORate: 1e6},
+		{PID: 4, CPUPercent: 1, RSS: 1, IORate: 1},
+	}
+
+	// Act
+	top := topProcesses(procs, 1)
+
+	// Assert - the leader of every column survives, sorted by CPU
+	if len(top) != 3 {
+		t.Fatalf("Expected 3 processes, got %+v", top)
+	}
+	if top[0].PID != 1 {
+		t.Errorf("Expected CPU leader first, got PID %d", top[0].PID)
+	}
+	for _, p := range top {
+		if p.PID == 4 {
+			t.Error("Expected PID 4 to be dropped - it leads no column")
+		}
+	}
+}
+
+func TestSortProcesses(t *testing.T) {
+	procs := []ProcessInfo{
+		{PID: 3, CPUPercent: 5, RSS: 300, IORate: 1},
+		{PID: 1, CPUPercent: 5, RSS: 100, IORate: 3},
+		{PID: 2, CPUPercent: 9, RSS: 200, IORate: 2},
+	}
+
+	tests := []struct {
+		by   processSort
+		want []int32
+	}{
+		{by: sortByCPU, want: []int32{2, 1, 3}}, // Tie broken by PID
+		{by: sortByMemory, want: []int32{3, 2, 1}},
+		{by: sortByIO, want: []int32{1, 2, 3}},
+		{by: sortByPID, want: []int32{1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.by.String(), func(t *testing.T) {
+			sortProcesses(procs, tt.by)
+			for i, pid := range tt.want {
+				if procs[i].PID != pid {
+					t.Errorf("Expected PID %d at row %d, got %d", pid, i, procs[i].PID)
+				}
+			}
+		})
+	}
+}
+
+func TestRealProcProvider(t *testing.T) {
+	// Act - list the processes of the machine running the test
+	samples, err := realProcProvider{}.ProcessesWithContext(context.Background())
+
+	// Assert - at least this test binary must show up
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	self := int32(os.Getpid())
+	for _, s := range samples {
+		if s.PID == self {
+			if s.Command == "" {
+				t.Errorf("Expected a command for this process, got %+v", s)
+			}
+			return
+		}
+	}
+	t.Errorf("Expected to find this process (PID %d) among %d processes", self, len(samples))
+}
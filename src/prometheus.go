@@ -0,0 +1,310 @@
+// Package main provides the Prometheus exporter for the hardware monitor.
+// This file serves the latest snapshot in the Prometheus text exposition format,
+// written by hand so the monitor doesn't need the client library.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// metricPrefix namespaces every exported metric.
+const metricPrefix = "hwmon_"
+
+// PrometheusExporter keeps the latest snapshot and serves it on /metrics.
+// It is a Sink, so it sees every collection even when the display falls behind.
+type PrometheusExporter struct {
+	mu     sync.Mutex
+	latest SystemStats
+	seen   bool              // True once the first snapshot arrived
+	errors map[string]uint64 // Failed collections per collector, since startup
+}
+
+// NewPrometheusExporter creates an exporter with no data yet.
+func NewPrometheusExporter() *PrometheusExporter {
+	return &PrometheusExporter{errors: make(map[string]uint64)}
+}
+
+// Observe records a finished snapshot and counts its failed collectors.
+// Rate collectors waiting for their baseline don't count.
+func (p *PrometheusExporter) Observe(stats SystemStats) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.latest = stats
+	p.seen = true
+	for name, status := range stats.Status {
+		if status.Failed() {
+			p.errors[name]++
+		}
+	}
+}
+
+// startMetricsServer serves exporter on /metrics at addr in the background.
+// The address is bound before returning, so "address already in use" is
+// reported at startup instead of being lost in a goroutine.
+func startMetricsServer(addr string, exporter http.Handler) (*http.Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exporter)
+	server := &http.Server{Handler: mux}
+
+	go func() {
+		if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("metrics server stopped: %v", err)
+		}
+	}()
+	return server, nil
+}
+
+// ServeHTTP writes the metrics in the text exposition format.
+func (p *PrometheusExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	stats, seen := p.latest, p.seen
+	errorCounts := make(map[string]uint64, len(p.errors))
+	for name, count := range p.errors {
+		errorCounts[name] = count
+	}
+	p.mu.Unlock()
+
+	// Every enabled collector gets an error counter, even before its first failure
+	for _, name := range config.Collectors {
+		if _, found := errorCounts[name]; !found {
+			errorCounts[name] = 0
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeMetrics(w, stats, seen, errorCounts)
+}
+
+// writeMetrics renders every metric family.
+// Values of failed collectors are left out rather than exported as 0.
+func writeMetrics(w io.Writer, stats SystemStats, seen bool, errorCounts map[string]uint64) {
+	m := &metricWriter{w: w}
+
+	// COLLECTOR HEALTH - always present, so alerts can fire on failures
+	m.family("collector_errors_total", "counter", "Collections that failed or timed out, per collector.")
+	for _, name := range sortedKeys(errorCounts) {
+		m.sample("collector_errors_total", labels("collector", name), float64(errorCounts[name]))
+	}
+	if !seen {
+		return // Nothing collected yet
+	}
+
+	m.family("collector_up", "gauge", "Whether the collector worked in the last collection (1) or failed (0).")
+	for _, name := range sortedKeys(stats.Status) {
+		up := 0.0
+		if !stats.Status[name].Failed() {
+			up = 1
+		}
+		m.sample("collector_up", labels("collector", name), up)
+	}
+
+	m.family("last_scrape_duration_seconds", "gauge", "How long the last collection took.")
+	m.sample("last_scrape_duration_seconds", "", stats.Duration.Seconds())
+	m.family("last_scrape_timestamp_seconds", "gauge", "Unix time the last collection finished.")
+	m.sample("last_scrape_timestamp_seconds", "", float64(stats.Time.UnixNano())/1e9)
+
+	// ALERTS - one series per active alert from the built-in rules
+	m.family("alert_active", "gauge", "Active alerts from the built-in alert rules, by metric, instance and level.")
+	for _, a := range stats.Alerts {
+		m.sample("alert_active", labels("metric", a.Metric, "instance", a.Instance, "level", a.Level.String()), 1)
+	}
+
+	// CPU
+	if stats.Succeeded("cpu") {
+		m.family("cpu_usage_percent", "gauge", "Overall CPU usage.")
+		m.sample("cpu_usage_percent", "", stats.CPUUsage)
+	}
+	if stats.Succeeded("cores") {
+		m.family("cpu_core_usage_percent", "gauge", "CPU usage per logical core.")
+		for i, usage := range stats.CoreUsage {
+			m.sample("cpu_core_usage_percent", labels("core", strconv.Itoa(i)), usage)
+		}
+	}
+
+	// MEMORY - SystemStats keeps GB, Prometheus convention is base units
+	if stats.Succeeded("memory") {
+		m.family("memory_usage_percent", "gauge", "Memory in use.")
+		m.sample("memory_usage_percent", "", stats.MemoryUsage)
+		m.family("memory_used_bytes", "gauge", "Memory in use, in bytes.")
+		m.sample("memory_used_bytes", "", stats.MemoryUsed*float64(config.BytesToGB))
+		m.family("memory_total_bytes", "gauge", "Total memory, in bytes.")
+		m.sample("memory_total_bytes", "", stats.MemoryTotal*float64(config.BytesToGB))
+		if stats.MemoryDetail != nil {
+			m.family("memory_available_bytes", "gauge", "Memory programs can still get without swapping, in bytes.")
+			m.sample("memory_available_bytes", "", float64(stats.MemoryDetail.Available))
+		}
+		m.family("swap_usage_percent", "gauge", "Swap in use.")
+		m.sample("swap_usage_percent", "", stats.SwapUsage)
+		m.family("swap_used_bytes", "gauge", "Swap in use, in bytes.")
+		m.sample("swap_used_bytes", "", stats.SwapUsed*float64(config.BytesToGB))
+		m.family("swap_total_bytes", "gauge", "Total swap, in bytes.")
+		m.sample("swap_total_bytes", "", stats.SwapTotal*float64(config.BytesToGB))
+	}
+
+	// DISKS - the monitored disk and every reported mount, labelled by path
+	disks := diskSamples(stats)
+	if len(disks) > 0 {
+		m.family("disk_usage_percent", "gauge", "Filesystem space in use.")
+		for _, d := range disks {
+			m.sample("disk_usage_percent", labels("path", d.Path), d.UsedPercent)
+		}
+		m.family("disk_used_bytes", "gauge", "Filesystem space in use, in bytes.")
+		for _, d := range disks {
+			m.sample("disk_used_bytes", labels("path", d.Path), float64(d.Used))
+		}
+		m.family("disk_total_bytes", "gauge", "Filesystem size, in bytes.")
+		for _, d := range disks {
+			m.sample("disk_total_bytes", labels("path", d.Path), float64(d.Total))
+		}
+	}
+
+	// NETWORK - rates per interface; errors and drops only exist as deltas,
+	// so they are gauges for the last interval rather than counters
+	if stats.Succeeded("network") {
+		iface := func(n NetInterfaceInfo) string { return labels("interface", n.Name) }
+		itemGauge(m, "network_receive_bytes_per_second", "Bytes received per second, per interface.", stats.Network, iface,
+			func(n NetInterfaceInfo) float64 { return n.RxBytesPerSec })
+		itemGauge(m, "network_transmit_bytes_per_second", "Bytes sent per second, per interface.", stats.Network, iface,
+			func(n NetInterfaceInfo) float64 { return n.TxBytesPerSec })
+		itemGauge(m, "network_receive_packets_per_second", "Packets received per second, per interface.", stats.Network, iface,
+			func(n NetInterfaceInfo) float64 { return n.RxPacketsPerSec })
+		itemGauge(m, "network_transmit_packets_per_second", "Packets sent per second, per interface.", stats.Network, iface,
+			func(n NetInterfaceInfo) float64 { return n.TxPacketsPerSec })
+		itemGauge(m, "network_receive_errors", "Receive errors since the previous collection, per interface.", stats.Network, iface,
+			func(n NetInterfaceInfo) float64 { return float64(n.RxErrors) })
+		itemGauge(m, "network_transmit_errors", "Transmit errors since the previous collection, per interface.", stats.Network, iface,
+			func(n NetInterfaceInfo) float64 { return float64(n.TxErrors) })
+		itemGauge(m, "network_receive_drops", "Received packets dropped since the previous collection, per interface.", stats.Network, iface,
+			func(n NetInterfaceInfo) float64 { return float64(n.RxDrops) })
+		itemGauge(m, "network_transmit_drops", "Outgoing packets dropped since the previous collection, per interface.", stats.Network, iface,
+			func(n NetInterfaceInfo) float64 { return float64(n.TxDrops) })
+	}
+
+	// DISK I/O - rates per device, await in seconds
+	if stats.Succeeded("diskio") {
+		device := func(d DiskIOInfo) string { return labels("device", d.Name) }
+		itemGauge(m, "diskio_read_bytes_per_second", "Bytes read per second, per device.", stats.DiskIO, device,
+			func(d DiskIOInfo) float64 { return d.ReadBytesPerSec })
+		itemGauge(m, "diskio_write_bytes_per_second", "Bytes written per second, per device.", stats.DiskIO, device,
+			func(d DiskIOInfo) float64 { return d.WriteBytesPerSec })
+		itemGauge(m, "diskio_read_ops_per_second", "Completed reads per second, per device.", stats.DiskIO, device,
+			func(d DiskIOInfo) float64 { return d.ReadIOPS })
+		itemGauge(m, "diskio_write_ops_per_second", "Completed writes per second, per device.", stats.DiskIO, device,
+			func(d DiskIOInfo) float64 { return d.WriteIOPS })
+		itemGauge(m, "diskio_util_percent", "Share of time the device was busy, per device.", stats.DiskIO, device,
+			func(d DiskIOInfo) float64 { return d.UtilPercent })
+		itemGauge(m, "diskio_await_seconds", "Average time per completed I/O including queueing, per device.", stats.DiskIO, device,
+			func(d DiskIOInfo) float64 { return d.AwaitMs / 1000 })
+	}
+
+	// HOST - load averages, uptime and the identity facts as an info series
+	if stats.Succeeded("host") && stats.Host != nil {
+		h := stats.Host
+		m.family("load1", "gauge", "Load average over 1 minute.")
+		m.sample("load1", "", h.Load1)
+		m.family("load5", "gauge", "Load average over 5 minutes.")
+		m.sample("load5", "", h.Load5)
+		m.family("load15", "gauge", "Load average over 15 minutes.")
+		m.sample("load15", "", h.Load15)
+		if h.LogicalCores > 0 {
+			m.family("cpu_logical_cores", "gauge", "Hardware threads; a load equal to this means every core is busy.")
+			m.sample("cpu_logical_cores", "", float64(h.LogicalCores))
+		}
+		if !h.BootTime.IsZero() {
+			m.family("uptime_seconds", "gauge", "Time since the machine booted.")
+			m.sample("uptime_seconds", "", h.Uptime(stats.Time).Seconds())
+		}
+		m.family("host_info", "gauge", "Machine identity, always 1.")
+		m.sample("host_info", labels("hostname", h.Hostname, "os", h.OS, "kernel", h.Kernel, "cpu_model", h.CPUModel), 1)
+	}
+}
+
+// itemGauge writes a gauge family with one sample per item, e.g. per
+// network interface. Nothing is written for an empty list.
+func itemGauge[T any](m *metricWriter, name, help string, items []T, label func(T) string, value func(T) float64) {
+	if len(items) == 0 {
+		return
+	}
+	m.family(name, "gauge", help)
+	for _, item := range items {
+		m.sample(name, label(item), value(item))
+	}
+}
+
+// diskSamples merges the monitored disk and the mounts into one list,
+// so a path reported by both collectors is exported once.
+func diskSamples(stats SystemStats) []MountInfo {
+	var disks []MountInfo
+	seen := make(map[string]bool)
+
+	if stats.Succeeded("disk") {
+		disks = append(disks, MountInfo{
+			Path:        stats.DiskPath,
+			UsedPercent: stats.DiskUsage,
+			Used:        uint64(stats.DiskUsed * float64(config.BytesToGB)),
+			Total:       uint64(stats.DiskTotal * float64(config.BytesToGB)),
+		})
+		seen[stats.DiskPath] = true
+	}
+	if stats.Succeeded("mounts") {
+		for _, mount := range stats.Mounts {
+			if !seen[mount.Path] {
+				disks = append(disks, mount)
+				seen[mount.Path] = true
+			}
+		}
+	}
+	return disks
+}
+
+// metricWriter writes exposition-format lines, adding the metric prefix.
+type metricWriter struct {
+	w io.Writer
+}
+
+// family writes the HELP and TYPE header that precedes a metric's samples.
+func (m *metricWriter) family(name, kind, help string) {
+	fmt.Fprintf(m.w, "# HELP %s%s %s\n# TYPE %s%s %s\n", metricPrefix, name, help, metricPrefix, name, kind)
+}
+
+// sample writes one value; labels comes from the labels function.
+func (m *metricWriter) sample(name, labels string, value float64) {
+	fmt.Fprintf(m.w, "%s%s%s %s\n", metricPrefix, name, labels, strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// labels formats name/value pairs as {name="value",...}, escaping the values.
+func labels(pairs ...string) string {
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, pairs[i], labelEscaper.Replace(pairs[i+1])))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// labelEscaper escapes the three characters the exposition format requires.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// sortedKeys returns the keys of m in order, for stable output.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
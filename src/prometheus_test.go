@@ -0,0 +1,223 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scrape serves one /metrics request and returns the body.
+func scrape(t *testing.T, exporter *PrometheusExporter) string {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	exporter.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", recorder.Code)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Errorf("Expected Prometheus text content type, got %q", contentType)
+	}
+	return recorder.Body.String()
+}
+
+func TestPrometheusExporter(t *testing.T) {
+	originalCollectors := config.Collectors
+	config.Collectors = []string{"cpu", "memory", "disk", "mounts"}
+	defer func() { config.Collectors = originalCollectors }()
+
+	t.Run("BeforeFirstSnapshot", func(t *testing.T) {
+		body := scrape(t, NewPrometheusExporter())
+
+		// Error counters exist from the start, values don't
+		if !strings.Contains(body, `hwmon_collector_errors_total{collector="disk"} 0`) {
+			t.Errorf("Expected zero error counter for disk, got:\n%s", body)
+		}
+		if strings.Contains(body, "hwmon_cpu_usage_percent") {
+			t.Errorf("Expected no CPU value before the first snapshot, got:\n%s", body)
+		}
+	})
+
+	t.Run("NoBaseline", func(t *testing.T) {
+		// Arrange - every start begins without rates
+		originalCollectors := config.Collectors
+		config.Collectors = []string{"diskio", "network"}
+		defer func() { config.Collectors = originalCollectors }()
+		stats := SystemStats{Time: time.Unix(1700000000, 0)}
+		stats.setStatus("diskio", ErrNoBaseline)
+		stats.setStatus("network", ErrNoBaseline)
+		exporter := NewPrometheusExporter()
+
+		// Act
+		exporter.Observe(stats)
+		body := scrape(t, exporter)
+
+		// Assert - waiting for a second sample is not an error
+		for _, line := range []string{
+			`hwmon_collector_errors_total{collector="diskio"} 0`,
+			`hwmon_collector_up{collector="diskio"} 1`,
+			`hwmon_collector_errors_total{collector="network"} 0`,
+			`hwmon_collector_up{collector="network"} 1`,
+		} {
+			if !strings.Contains(body, line+"\n") {
+				t.Errorf("Expected line %q in output:\n%s", line, body)
+			}
+		}
+	})
+
+	t.Run("Snapshot", func(t *testing.T) {
+		// Arrange - disk and mounts report the same path, memory failed
+		stats := SystemStats{
+			Time:      time.Unix(1700000000, 0),
+			Duration:  250 * time.Millisecond,
+			CPUUsage:  42.5,
+			DiskUsage: 50,
+			DiskUsed:  1,
+			DiskTotal: 2,
+			DiskPath:  "/",
+			Mounts: []MountInfo{
+				{Path: "/", UsedPercent: 50, Used: 1 << 30, Total: 2 << 30},
+				{Path: `/mnt/we"ird`, UsedPercent: 10, Used: 100, Total: 1000},
+			},
+			Alerts: []Alert{{Metric: "disk.used_percent", Instance: "/", Level: LevelWarning, State: AlertActive}},
+		}
+		stats.setStatus("cpu", nil)
+		stats.setStatus("memory", errors.New("memory error"))
+		stats.setStatus("disk", nil)
+		stats.setStatus("mounts", nil)
+
+		exporter := NewPrometheusExporter()
+
+		// Act - observe twice so the error counter accumulates
+		exporter.Observe(stats)
+		exporter.Observe(stats)
+		body := scrape(t, exporter)
+
+		// Assert
+		expected := []string{
+			"# TYPE hwmon_cpu_usage_percent gauge",
+			"hwmon_cpu_usage_percent 42.5",
+			`hwmon_disk_used_bytes{path="/"} 1073741824`,
+			`hwmon_disk_total_bytes{path="/mnt/we\"ird"} 1000`,
+			"# TYPE hwmon_collector_errors_total counter",
+			`hwmon_collector_errors_total{collector="memory"} 2`,
+			`hwmon_collector_errors_total{collector="cpu"} 0`,
+			`hwmon_collector_up{collector="memory"} 0`,
+			"hwmon_last_scrape_duration_seconds 0.25",
+			"hwmon_last_scrape_timestamp_seconds 1700000000",
+			`hwmon_alert_active{metric="disk.used_percent",instance="/",level="warning"} 1`,
+		}
+		for _, line := range expected {
+			if !strings.Contains(body, line+"\n") {
+				t.Errorf("Expected line %q in output:\n%s", line, body)
+			}
+		}
+
+		// A failed metric is left out instead of being exported as 0
+		if strings.Contains(body, "hwmon_memory_usage_percent") {
+			t.Errorf("Expected no memory values after a failure, got:\n%s", body)
+		}
+		// The root filesystem is exported once, not once per collector
+		if count := strings.Count(body, `hwmon_disk_used_bytes{path="/"}`); count != 1 {
+			t.Errorf("Expected one sample for path /, got %d", count)
+		}
+	})
+}
+
+func TestPrometheusExporterCollectors(t *testing.T) {
+	tests := []struct {
+		name     string
+		stats    SystemStats
+		expected []string
+	}{
+		{
+			name: "network",
+			stats: SystemStats{Network: []NetInterfaceInfo{
+				{Name: "eth0", RxBytesPerSec: 2048, TxBytesPerSec: 512, RxPacketsPerSec: 10, TxPacketsPerSec: 5, RxErrors: 2, TxDrops: 1},
+				{Name: "wlan0"},
+			}},
+			expected: []string{
+				"# TYPE hwmon_network_receive_bytes_per_second gauge",
+				`hwmon_network_receive_bytes_per_second{interface="eth0"} 2048`,
+				`hwmon_network_transmit_bytes_per_second{interface="eth0"} 512`,
+				`hwmon_network_receive_packets_per_second{interface="eth0"} 10`,
+				`hwmon_network_transmit_packets_per_second{interface="eth0"} 5`,
+				`hwmon_network_receive_errors{interface="eth0"} 2`,
+				`hwmon_network_transmit_drops{interface="eth0"} 1`,
+				`hwmon_network_receive_bytes_per_second{interface="wlan0"} 0`,
+			},
+		},
+		{
+			name: "diskio",
+			stats: SystemStats{DiskIO: []DiskIOInfo{
+				{Name: "sda", ReadBytesPerSec: 4096, WriteBytesPerSec: 1024, ReadIOPS: 3, WriteIOPS: 2, UtilPercent: 12.5, AwaitMs: 4},
+			}},
+			expected: []string{
+				"# TYPE hwmon_diskio_read_bytes_per_second gauge",
+				`hwmon_diskio_read_bytes_per_second{device="sda"} 4096`,
+				`hwmon_diskio_write_bytes_per_second{device="sda"} 1024`,
+				`hwmon_diskio_read_ops_per_second{device="sda"} 3`,
+				`hwmon_diskio_write_ops_per_second{device="sda"} 2`,
+				`hwmon_diskio_util_percent{device="sda"} 12.5`,
+				`hwmon_diskio_await_seconds{device="sda"} 0.004`,
+			},
+		},
+		{
+			name: "host",
+			stats: SystemStats{
+				Time: time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+				Host: &HostInfo{
+					Hostname: "web-1", OS: "ubuntu 24.04", Kernel: "6.8.0-45-generic", CPUModel: "AMD Ryzen 7 7840U",
+					LogicalCores: 16, BootTime: time.Date(2026, 10, 16, 11, 0, 0, 0, time.UTC),
+					Load1: 1.5, Load5: 0.75, Load15: 0.25,
+				},
+			},
+			expected: []string{
+				"# TYPE hwmon_load1 gauge",
+				"hwmon_load1 1.5",
+				"hwmon_load5 0.75",
+				"hwmon_load15 0.25",
+				"hwmon_cpu_logical_cores 16",
+				"hwmon_uptime_seconds 3600",
+				`hwmon_host_info{hostname="web-1",os="ubuntu 24.04",kernel="6.8.0-45-generic",cpu_model="AMD Ryzen 7 7840U"} 1`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			stats := tt.stats
+			stats.setStatus(tt.name, nil)
+			failed := SystemStats{}
+			failed.setStatus(tt.name, ErrNoBaseline)
+
+			// Act
+			var body, failedBody strings.Builder
+			writeMetrics(&body, stats, true, nil)
+			writeMetrics(&failedBody, failed, true, nil)
+
+			// Assert - and nothing at all without a fresh value
+			for _, line := range tt.expected {
+				if !strings.Contains(body.String(), line+"\n") {
+					t.Errorf("Expected line %q in output:\n%s", line, body.String())
+				}
+			}
+			for _, line := range tt.expected {
+				if name, _, _ := strings.Cut(strings.TrimPrefix(line, "# TYPE "), " "); strings.Contains(failedBody.String(), name) {
+					t.Errorf("Expected no %s values while stale, got:\n%s", name, failedBody.String())
+				}
+			}
+		})
+	}
+}
+
+func TestLabelsEscaping(t *testing.T) {
+	got := labels("path", "C:\\data\n\"x\"")
+	want := `{path="C:\\data\n\"x\""}`
+	if got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,135 @@
+// Package main provides session recording for the hardware monitor.
+// This file contains the Recorder, which writes every snapshot to a session
+// file for --record, and LoadRecording, which reads one back for --replay.
+//
+// A session file holds one JSON snapshot per line, the same format as
+// --output=jsonl, gzip-compressed when the file name ends in ".gz".
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Recorder is a Sink that appends every snapshot to a session file.
+// Each snapshot is flushed to disk as soon as it is written, so a recording
+// of a machine that crashes mid-incident is still readable up to the crash.
+type Recorder struct {
+	mu     sync.Mutex
+	path   string
+	file   *os.File
+	gz     *gzip.Writer // Nil for uncompressed files
+	out    io.Writer    // gz or file
+	err    error        // First write error; recording stops after it
+	closed bool
+}
+
+// NewRecorder creates (or truncates) the session file at path.
+func NewRecorder(path string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+
+	r := &Recorder{path: path, file: file, out: file}
+	if strings.HasSuffix(path, ".gz") {
+		r.gz = gzip.NewWriter(file)
+		r.out = r.gz
+	}
+	return r, nil
+}
+
+// Observe implements Sink by appending the snapshot to the file.
+func (r *Recorder) Observe(stats SystemStats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed || r.err != nil {
+		return // Keep the file as it was before the first failure
+	}
+	if err := writeJSONLine(r.out, stats); err != nil {
+		r.err = err
+		return
+	}
+	// Push the compressed block out, or a crash would lose it
+	if r.gz != nil {
+		r.err = r.gz.Flush()
+	}
+}
+
+// Close finishes the file and reports the first error seen while recording.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return r.err
+	}
+	r.closed = true
+
+	if r.gz != nil {
+		if err := r.gz.Close(); err != nil && r.err == nil {
+			r.err = err
+		}
+	}
+	if err := r.file.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	if r.err != nil {
+		return fmt.Errorf("recording to %s failed: %w", r.path, r.err)
+	}
+	return nil
+}
+
+// LoadRecording reads every snapshot from a session file, oldest first.
+// Compression is detected from the content, not the file name. A file that
+// ends mid-snapshot - the recorder was killed - is read up to the break.
+func LoadRecording(path string) ([]SystemStats, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer file.Close()
+
+	// GZIP DETECTION - every gzip stream starts with the bytes 1f 8b
+	in := bufio.NewReader(file)
+	var reader io.Reader = in
+	if magic, _ := in.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(in)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recording %s: %w", path, err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	// DECODE SNAPSHOTS - one JSON object after another
+	var frames []SystemStats
+	decoder := json.NewDecoder(reader)
+	for {
+		var stats SystemStats
+		err := decoder.Decode(&stats)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break // End of file, or the tail of an interrupted recording
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recording %s after %d snapshots: %w", path, len(frames), err)
+		}
+		frames = append(frames, stats)
+	}
+
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("recording %s holds no snapshots", path)
+	}
+	// Snapshots are written in order, but be safe - replay relies on it
+	sort.SliceStable(frames, func(i, j int) bool { return frames[i].Time.Before(frames[j].Time) })
+	return frames, nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordedFrames returns snapshots one second apart, with a failed disk in the second.
+func recordedFrames() []SystemStats {
+	start := time.Unix(1700000000, 0).UTC()
+	frames := make([]SystemStats, 3)
+	for i := range frames {
+		frames[i] = SystemStats{Time: start.Add(time.Duration(i) * time.Second), CPUUsage: float64(10 * (i + 1))}
+		frames[i].setStatus("cpu", nil)
+		frames[i].setStatus("disk", nil)
+	}
+	frames[1].setStatus("disk", errors.New("disk error"))
+	return frames
+}
+
+// record writes frames to a new session file and returns its path.
+func record(t *testing.T, name string, frames []SystemStats) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	recorder, err := NewRecorder(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, stats := range frames {
+		recorder.Observe(stats)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return path
+}
+
+func TestRecording(t *testing.T) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		for _, name := range []string{"session.jsonl", "session.jsonl.gz"} {
+			t.Run(name, func(t *testing.T) {
+				// Arrange
+				path := record(t, name, recordedFrames())
+
+				// Act
+				frames, err := LoadRecording(path)
+
+				// Assert
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if len(frames) != 3 {
+					t.Fatalf("Expected 3 snapshots, got %d", len(frames))
+				}
+				if frames[2].CPUUsage != 30 || !frames[2].Time.Equal(recordedFrames()[2].Time) {
+					t.Errorf("Unexpected last snapshot %+v", frames[2])
+				}
+				if status, _ := frames[1].StatusOf("disk"); status.State != StateError || status.Error != "disk error" {
+					t.Errorf("Expected the disk failure to be recorded, got %+v", status)
+				}
+			})
+		}
+	})
+
+	t.Run("Compressed", func(t *testing.T) {
+		path := record(t, "session.jsonl.gz", recordedFrames())
+		data, _ := os.ReadFile(path)
+		if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+			t.Error("Expected a gzip file for a .gz name")
+		}
+	})
+
+	t.Run("Interrupted", func(t *testing.T) {
+		// Arrange - the recorder was killed before closing the file
+		path := filepath.Join(t.TempDir(), "session.jsonl.gz")
+		recorder, _ := NewRecorder(path)
+		for _, stats := range recordedFrames() {
+			recorder.Observe(stats)
+		}
+		data, _ := os.ReadFile(path) // Flushed, but without the gzip trailer
+		recorder.Close()
+		os.WriteFile(path, data, 0o600)
+
+		// Act
+		frames, err := LoadRecording(path)
+
+		// Assert - everything flushed before the "crash" is still there
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(frames) != 3 {
+			t.Errorf("Expected 3 snapshots, got %d", len(frames))
+		}
+	})
+
+	t.Run("ObserveAfterClose", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "session.jsonl")
+		recorder, _ := NewRecorder(path)
+		recorder.Close()
+		recorder.Observe(recordedFrames()[0]) // The sampler may still finish a collection
+
+		if data, _ := os.ReadFile(path); len(data) != 0 {
+			t.Errorf("Expected nothing written after Close, got %q", data)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		dir := t.TempDir()
+		empty := filepath.Join(dir, "empty.jsonl")
+		os.WriteFile(empty, nil, 0o600)
+		garbage := filepath.Join(dir, "garbage.jsonl")
+		os.WriteFile(garbage, []byte("{\"cpu_usage\": 1}\nnot json\n"), 0o600)
+
+		tests := []struct {
+			name string
+			path string
+			want string
+		}{
+			{name: "Missing", path: filepath.Join(dir, "missing.jsonl"), want: "failed to open recording"},
+			{name: "Empty", path: empty, want: "holds no snapshots"},
+			{name: "Garbage", path: garbage, want: "after 1 snapshots"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if _, err := LoadRecording(tt.path); err == nil || !strings.Contains(err.Error(), tt.want) {
+					t.Errorf("Expected error containing %q, got %v", tt.want, err)
+				}
+			})
+		}
+	})
+}
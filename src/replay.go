@@ -0,0 +1,321 @@
+// Package main provides session replay for the hardware monitor.
+// This file contains the ReplayMonitor, a SystemMonitor that plays back a
+// session recorded with --record, so an incident can be rewatched in the
+// same dashboard it happened in.
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Playback speeds accepted by --replay-speed and reached with the speed keys.
+const (
+	minReplaySpeed = 0.125
+	maxReplaySpeed = 1024.0
+)
+
+// ReplayMonitor plays back recorded snapshots through the SystemMonitor
+// interface. Every method returns what the snapshot at the playback position
+// held - collector failures included - so a replay runs through the same
+// collectors, alert rules and display as a live session.
+//
+// Playback follows the recorded timestamps: the app calls Advance before
+// each collection, which moves the position by the wall-clock time since the
+// previous call times the speed.
+type ReplayMonitor struct {
+	mu       sync.RWMutex
+	frames   []SystemStats // Recorded snapshots, oldest first
+	index    int           // Snapshot being shown
+	position time.Time     // Recorded time being played, at or after frames[index].Time
+	playing  bool
+	speed    float64   // Recorded time per wall-clock time, 1 for real time
+	lastTick time.Time // Wall clock of the previous Advance, zero after a pause
+}
+
+// ReplayStatus describes the playback position, for the display.
+type ReplayStatus struct {
+	Time    time.Time     // When the snapshot shown was recorded
+	Elapsed time.Duration // How far into the recording that is
+	Length  time.Duration // Time between the first and last snapshot
+	Speed   float64
+	Playing bool
+}
+
+// NewReplayMonitor creates a monitor that starts playing frames from the first one.
+// frames must not be empty - LoadRecording never returns an empty recording.
+func NewReplayMonitor(frames []SystemStats, speed float64) *ReplayMonitor {
+	return &ReplayMonitor{
+		frames:   frames,
+		position: frames[0].Time,
+		playing:  len(frames) > 1,
+		speed:    min(max(speed, minReplaySpeed), maxReplaySpeed),
+	}
+}
+
+// Advance moves playback on by the time since the previous call, scaled by
+// the speed. Call it between collections, so all collectors of one snapshot
+// see the same frame. Playback pauses on the last frame.
+func (r *ReplayMonitor) Advance(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.playing {
+		r.lastTick = time.Time{}
+		return
+	}
+	if !r.lastTick.IsZero() {
+		elapsed := now.Sub(r.lastTick)
+		r.moveTo(r.position.Add(time.Duration(float64(elapsed) * r.speed)))
+	}
+	r.lastTick = now
+
+	if r.index == len(r.frames)-1 {
+		r.playing = false
+	}
+}
+
+// TogglePause pauses or resumes playback.
+// Resuming on the last frame starts over from the beginning.
+func (r *ReplayMonitor) TogglePause() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.playing = !r.playing
+	r.lastTick = time.Time{} // Time spent paused doesn't count
+	if r.playing && r.index == len(r.frames)-1 {
+		r.moveTo(r.frames[0].Time)
+	}
+}
+
+// Faster doubles the playback speed, up to maxReplaySpeed.
+func (r *ReplayMonitor) Faster() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.speed = min(r.speed*2, maxReplaySpeed)
+}
+
+// Slower halves the playback speed, down to minReplaySpeed.
+func (r *ReplayMonitor) Slower() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.speed = max(r.speed/2, minReplaySpeed)
+}
+
+// Seek jumps forwards (or backwards, for a negative offset) in the recording.
+// The position stays within the first and last snapshot.
+func (r *ReplayMonitor) Seek(offset time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.moveTo(r.position.Add(offset))
+}
+
+// moveTo sets the playback position and the snapshot shown there.
+// The caller must hold r.mu.
+func (r *ReplayMonitor) moveTo(position time.Time) {
+	first, last := r.frames[0].Time, r.frames[len(r.frames)-1].Time
+	if position.Before(first) {
+		position = first
+	}
+	if position.After(last) {
+		position = last
+	}
+	r.position = position
+
+	// The newest snapshot recorded at or before the position
+	next := sort.Search(len(r.frames), func(i int) bool { return r.frames[i].Time.After(position) })
+	r.index = max(next-1, 0)
+}
+
+// Status returns the current playback position.
+func (r *ReplayMonitor) Status() ReplayStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	first := r.frames[0].Time
+	return ReplayStatus{
+		Time:    r.frames[r.index].Time,
+		Elapsed: r.frames[r.index].Time.Sub(first),
+		Length:  r.frames[len(r.frames)-1].Time.Sub(first),
+		Speed:   r.speed,
+		Playing: r.playing,
+	}
+}
+
+// Finished reports whether playback has stopped at the end and stats is the
+// last recorded snapshot, i.e. there is nothing left to show.
+func (r *ReplayMonitor) Finished(stats SystemStats) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return !r.playing && r.index == len(r.frames)-1 && stats.Time.Equal(r.frames[r.index].Time)
+}
+
+// Collectors returns the registered collectors that appear in the recording,
+// in registry order, so a replay shows what was recorded rather than what
+// this machine would collect.
+func (r *ReplayMonitor) Collectors() []string {
+	recorded := make(map[string]bool)
+	for _, stats := range r.frames {
+		for name := range stats.Status {
+			recorded[name] = true
+		}
+	}
+
+	var names []string
+	for _, name := range collectors.Names() {
+		if recorded[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// DiskPath returns the path the recorded disk values are for, "" if none.
+func (r *ReplayMonitor) DiskPath() string {
+	for _, stats := range r.frames {
+		if stats.DiskPath != "" {
+			return stats.DiskPath
+		}
+	}
+	return ""
+}
+
+// Now implements Clock: replayed snapshots keep the time they were recorded at.
+func (r *ReplayMonitor) Now() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.frames[r.index].Time
+}
+
+// recordedError reproduces a collector failure with its original message.
+// A recorded timeout or missing baseline keeps its state rather than
+// becoming an error.
+type recordedError struct {
+	status MetricStatus
+}
+
+func (e recordedError) Error() string { return e.status.Error }
+
+func (e recordedError) Unwrap() error {
+	switch e.status.State {
+	case StateStale:
+		return ErrCollectorTimeout
+	case StatePending:
+		return ErrNoBaseline
+	}
+	return nil
+}
+
+// frame returns the snapshot being shown, or why the named collector has
+// nothing to report in it.
+func (r *ReplayMonitor) frame(name string) (SystemStats, error) {
+	r.mu.RLock()
+	stats := r.frames[r.index]
+	r.mu.RUnlock()
+
+	status, ok := stats.StatusOf(name)
+	switch {
+	case !ok:
+		return stats, fmt.Errorf("%s was not recorded at %s", name, stats.Time.Format(config.TimeFormat))
+	case status.State != StateOK:
+		return stats, recordedError{status}
+	}
+	return stats, nil
+}
+
+// GetCPUUsage implements SystemMonitor; the duration is ignored.
+func (r *ReplayMonitor) GetCPUUsage(_ time.Duration) (float64, error) {
+	stats, err := r.frame("cpu")
+	if err != nil {
+		return 0, err
+	}
+	return stats.CPUUsage, nil
+}
+
+// GetPerCoreUsage implements SystemMonitor; the duration is ignored.
+func (r *ReplayMonitor) GetPerCoreUsage(_ time.Duration) ([]float64, error) {
+	stats, err := r.frame("cores")
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(stats.CoreUsage), nil // Copied, so the recording can't be changed through it
+}
+
+// GetMemoryUsage implements SystemMonitor.
+func (r *ReplayMonitor) GetMemoryUsage() (*MemoryInfo, error) {
+	stats, err := r.frame("memory")
+	if err != nil {
+		return nil, err
+	}
+	if stats.MemoryDetail == nil {
+		return nil, fmt.Errorf("memory details were not recorded")
+	}
+	info := *stats.MemoryDetail // Copy, so the recording can't be changed through it
+	return &info, nil
+}
+
+// GetDiskUsage implements SystemMonitor for the recorded disk; the path is ignored.
+func (r *ReplayMonitor) GetDiskUsage(_ string) (*DiskInfo, error) {
+	stats, err := r.frame("disk")
+	if err != nil {
+		return nil, err
+	}
+	// The recording holds gigabytes; converting back is exact below 8 PiB
+	return &DiskInfo{
+		UsedPercent: stats.DiskUsage,
+		Used:        uint64(stats.DiskUsed * float64(config.BytesToGB)),
+		Total:       uint64(stats.DiskTotal * float64(config.BytesToGB)),
+	}, nil
+}
+
+// GetMountUsage implements SystemMonitor; the recording was filtered already.
+func (r *ReplayMonitor) GetMountUsage(_ MountFilter) ([]MountInfo, error) {
+	stats, err := r.frame("mounts")
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(stats.Mounts), nil
+}
+
+// GetDiskIO implements SystemMonitor; the recording was filtered already.
+func (r *ReplayMonitor) GetDiskIO(_ []string) ([]DiskIOInfo, error) {
+	stats, err := r.frame("diskio")
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(stats.DiskIO), nil
+}
+
+// GetProcesses implements SystemMonitor, returning every recorded process.
+func (r *ReplayMonitor) GetProcesses(_ int) ([]ProcessInfo, error) {
+	stats, err := r.frame("processes")
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(stats.Processes), nil
+}
+
+// GetNetworkUsage implements SystemMonitor.
+func (r *ReplayMonitor) GetNetworkUsage() ([]NetInterfaceInfo, error) {
+	stats, err := r.frame("network")
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(stats.Network), nil
+}
+
+// GetHostInfo implements SystemMonitor.
+func (r *ReplayMonitor) GetHostInfo() (*HostInfo, error) {
+	stats, err := r.frame("host")
+	if err != nil {
+		return nil, err
+	}
+	if stats.Host == nil {
+		return nil, fmt.Errorf("host info was not recorded")
+	}
+	info := *stats.Host
+	return &info, nil
+}
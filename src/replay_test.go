@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestReplayMonitorPlayback(t *testing.T) {
+	wall := time.Unix(1800000000, 0) // The wall clock while replaying
+	first := recordedFrames()[0].Time
+
+	t.Run("FollowsRecordedTime", func(t *testing.T) {
+		// Arrange
+		replay := NewReplayMonitor(recordedFrames(), 1)
+
+		// Act & Assert - the first Advance only starts the clock
+		replay.Advance(wall)
+		if got := replay.Now(); !got.Equal(first) {
+			t.Fatalf("Expected to start at %s, got %s", first, got)
+		}
+		replay.Advance(wall.Add(1500 * time.Millisecond))
+		if got := replay.Status().Elapsed; got != time.Second {
+			t.Errorf("Expected the snapshot recorded 1s in, got %s", got)
+		}
+
+		// Past the end - playback stops on the last snapshot
+		replay.Advance(wall.Add(time.Minute))
+		status := replay.Status()
+		if status.Playing || status.Elapsed != 2*time.Second || status.Length != 2*time.Second {
+			t.Errorf("Expected to stop at the end, got %+v", status)
+		}
+	})
+
+	t.Run("Speed", func(t *testing.T) {
+		replay := NewReplayMonitor(recordedFrames(), 1)
+		replay.Faster() // 2x
+
+		replay.Advance(wall)
+		replay.Advance(wall.Add(time.Second))
+
+		if got := replay.Status().Elapsed; got != 2*time.Second {
+			t.Errorf("Expected 2s played in 1s at 2x, got %s", got)
+		}
+
+		replay.Slower()
+		replay.Slower()
+		if got := replay.Status().Speed; got != 0.5 {
+			t.Errorf("Expected speed 0.5, got %g", got)
+		}
+		if got := NewReplayMonitor(recordedFrames(), 1e6).Status().Speed; got != maxReplaySpeed {
+			t.Errorf("Expected the speed to be capped at %g, got %g", maxReplaySpeed, got)
+		}
+	})
+
+	t.Run("Pause", func(t *testing.T) {
+		replay := NewReplayMonitor(recordedFrames(), 1)
+		replay.Advance(wall)
+		replay.TogglePause()
+
+		// Time spent paused doesn't move playback, nor count once resumed
+		replay.Advance(wall.Add(time.Minute))
+		replay.TogglePause()
+		replay.Advance(wall.Add(time.Minute + time.Second))
+		replay.Advance(wall.Add(time.Minute + 2*time.Second))
+
+		if got := replay.Status().Elapsed; got != time.Second {
+			t.Errorf("Expected 1s played, got %s", got)
+		}
+	})
+
+	t.Run("Seek", func(t *testing.T) {
+		replay := NewReplayMonitor(recordedFrames(), 1)
+
+		replay.Seek(1500 * time.Millisecond)
+		if got := replay.Status().Elapsed; got != time.Second {
+			t.Errorf("Expected the snapshot recorded 1s in, got %s", got)
+		}
+		replay.Seek(-time.Hour)
+		if got := replay.Now(); !got.Equal(first) {
+			t.Errorf("Expected seeking back to stop at the start, got %s", got)
+		}
+	})
+
+	t.Run("RestartAtEnd", func(t *testing.T) {
+		replay := NewReplayMonitor(recordedFrames(), 1)
+		replay.Seek(time.Hour)
+		replay.Advance(wall) // Stops on the last snapshot
+
+		replay.TogglePause()
+
+		if status := replay.Status(); !status.Playing || status.Elapsed != 0 {
+			t.Errorf("Expected playback to start over, got %+v", status)
+		}
+	})
+
+	t.Run("Finished", func(t *testing.T) {
+		frames := recordedFrames()
+		replay := NewReplayMonitor(frames, 1)
+		replay.Seek(time.Hour)
+
+		if replay.Finished(frames[2]) {
+			t.Error("Expected not finished while still playing")
+		}
+		replay.Advance(wall)
+		if !replay.Finished(frames[2]) || replay.Finished(frames[1]) {
+			t.Error("Expected finished once the last snapshot is shown")
+		}
+	})
+}
+
+func TestReplayMonitorSnapshots(t *testing.T) {
+	originalCollectors := config.Collectors
+	defer func() { config.Collectors = originalCollectors }()
+
+	t.Run("ThroughCollectors", func(t *testing.T) {
+		// Arrange - the replay decides which collectors run
+		frames := recordedFrames()
+		frames[0].DiskUsage, frames[0].DiskUsed, frames[0].DiskTotal = 50, 1.5, 3
+		replay := NewReplayMonitor(frames, 1)
+		config.Collectors = replay.Collectors()
+
+		// Act
+		statsCh := make(chan SystemStats, 1)
+		fetchSystemStats(context.Background(), replay, statsCh)
+		stats := <-statsCh
+
+		// Assert
+		if !stats.Time.Equal(frames[0].Time) {
+			t.Errorf("Expected the recorded time %s, got %s", frames[0].Time, stats.Time)
+		}
+		if stats.CPUUsage != 10 || stats.DiskUsage != 50 || stats.DiskUsed != 1.5 || stats.DiskTotal != 3 {
+			t.Errorf("Expected the recorded values, got %+v", stats)
+		}
+		if len(stats.Status) != 2 {
+			t.Errorf("Expected only the recorded cpu and disk, got %v", stats.Status)
+		}
+	})
+
+	t.Run("RecordedFailures", func(t *testing.T) {
+		frames := recordedFrames()
+		frames[2].setStatus("cpu", timeoutError("cpu", time.Second))
+		replay := NewReplayMonitor(frames, 1)
+		config.Collectors = []string{"cpu", "disk", "memory"}
+		fetch := func() SystemStats {
+			statsCh := make(chan SystemStats, 1)
+			fetchSystemStats(context.Background(), replay, statsCh)
+			return <-statsCh
+		}
+
+		replay.Seek(time.Second)
+		if status, _ := fetch().StatusOf("disk"); status.State != StateError || status.Error != "disk error" {
+			t.Errorf("Expected the recorded disk error, got %+v", status)
+		}
+
+		replay.Seek(time.Second)
+		stats := fetch()
+		if status, _ := stats.StatusOf("cpu"); status.State != StateStale || status.Error != "cpu: collector timed out after 1s" {
+			t.Errorf("Expected the recorded timeout to stay stale, got %+v", status)
+		}
+		if status, _ := stats.StatusOf("memory"); status.State != StateError {
+			t.Errorf("Expected an error for a collector that wasn't recorded, got %+v", status)
+		}
+	})
+}
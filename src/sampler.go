@@ -0,0 +1,111 @@
+// Package main provides background sampling for the hardware monitor.
+// This file contains the Sampler, which runs collections off the UI goroutine
+// and publishes each finished snapshot.
+package main
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Sampler runs fetchSystemStats in the background and publishes the results.
+// The display loop only ever receives finished snapshots, so a slow collector
+// (a long CPU sample, a hung network mount) can never freeze input handling.
+type Sampler struct {
+	ctx     context.Context // Cancelling it abandons the collection in flight
+	monitor SystemMonitor
+	running atomic.Bool      // True while a collection is in flight
+	updates chan SystemStats // Finished snapshots, newest wins
+	sinks   []Sink           // Also receive every snapshot, e.g. the Prometheus exporter
+	alerts  *AlertEngine     // Evaluates alert rules on every snapshot, nil for none
+}
+
+// Sink receives every finished snapshot, whether or not the display keeps up.
+// Observe is called from the sampler goroutine, one snapshot at a time,
+// and must not block.
+type Sink interface {
+	Observe(stats SystemStats)
+}
+
+// NewSampler creates a sampler that collects from the given monitor
+// until ctx is cancelled.
+func NewSampler(ctx context.Context, monitor SystemMonitor) *Sampler {
+	return &Sampler{
+		ctx:     ctx,
+		monitor: monitor,
+		updates: make(chan SystemStats, config.ChannelBuffer),
+	}
+}
+
+// AddSink registers a sink for every future snapshot.
+// Call it before the first Trigger - sinks are not guarded by a lock.
+func (s *Sampler) AddSink(sink Sink) {
+	s.sinks = append(s.sinks, sink)
+}
+
+// SetAlertEngine makes every future snapshot carry the engine's alerts.
+// Like AddSink, call it before the first Trigger.
+func (s *Sampler) SetAlertEngine(engine *AlertEngine) {
+	s.alerts = engine
+}
+
+// Updates returns the channel that receives every finished snapshot.
+func (s *Sampler) Updates() <-chan SystemStats {
+	return s.updates
+}
+
+// Trigger starts a collection in the background and returns immediately.
+// If the previous collection is still running nothing new is started and
+// Trigger returns false - piling up goroutines behind a hung call would only
+// make things worse.
+func (s *Sampler) Trigger() bool {
+	// Atomically flip running from false to true, or bail out
+	if s.ctx.Err() != nil {
+		return false // Shutting down
+	}
+	if !s.running.CompareAndSwap(false, true) {
+		return false
+	}
+
+	go s.collect()
+	return true
+}
+
+// collect runs one full collection and publishes its snapshot.
+func (s *Sampler) collect() {
+	statsCh := make(chan SystemStats, config.ChannelBuffer)
+	fetchSystemStats(s.ctx, s.monitor, statsCh)
+	stats := <-statsCh
+
+	// Alerts are part of the snapshot, so sinks and the display see the same ones
+	if s.alerts != nil {
+		s.alerts.Evaluate(&stats)
+	}
+
+	// Sinks run while we still hold the running flag, so they never overlap
+	for _, sink := range s.sinks {
+		sink.Observe(stats)
+	}
+
+	// Allow the next collection before publishing, so a consumer that
+	// triggers right after receiving isn't turned away
+	s.running.Store(false)
+	s.publish(stats)
+}
+
+// publish delivers a snapshot without ever blocking the sampler.
+// An unread older snapshot is dropped in favour of the new one.
+func (s *Sampler) publish(stats SystemStats) {
+	for {
+		select {
+		case s.updates <- stats:
+			return
+		default:
+			// Channel full - discard the stale snapshot and try again
+			select {
+			case <-s.updates:
+			default:
+			}
+		}
+	}
+}
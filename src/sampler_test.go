@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// blockingMonitor is a MockSystemMonitor whose CPU call waits until released,
+// simulating a collector that hangs (e.g. a stale network mount).
+type blockingMonitor struct {
+	*MockSystemMonitor
+	release chan struct{}
+}
+
+func (b *blockingMonitor) GetCPUUsage(duration time.Duration) (float64, error) {
+	<-b.release
+	return b.MockSystemMonitor.GetCPUUsage(duration)
+}
+
+func TestSamplerSkipsWhileRunning(t *testing.T) {
+	// Arrange - only the blocking CPU collector runs
+	originalCollectors := config.Collectors
+	config.Collectors = []string{"cpu"}
+	defer func() { config.Collectors = originalCollectors }()
+
+	monitor := &blockingMonitor{
+		MockSystemMonitor: &MockSystemMonitor{CPUUsage: 42.0},
+		release:           make(chan struct{}),
+	}
+	sampler := NewSampler(context.Background(), monitor)
+
+	// Act & Assert - the first trigger starts, the second is refused
+	if !sampler.Trigger() {
+		t.Fatal("Expected first Trigger to start a collection")
+	}
+	if sampler.Trigger() {
+		t.Error("Expected Trigger to be skipped while a collection is running")
+	}
+
+	// Nothing may be published while the collector is stuck
+	select {
+	case <-sampler.Updates():
+		t.Fatal("Received a snapshot before the collection finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Release the collector and expect the snapshot
+	close(monitor.release)
+	select {
+	case stats := <-sampler.Updates():
+		if stats.CPUUsage != 42.0 {
+			t.Errorf("Expected CPU usage 42.0, got %f", stats.CPUUsage)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for snapshot")
+	}
+
+	// Once finished, a new collection can start again
+	if !sampler.Trigger() {
+		t.Error("Expected Trigger to start a new collection after the previous one finished")
+	}
+	<-sampler.Updates()
+}
+
+func TestSamplerKeepsNewestSnapshot(t *testing.T) {
+	// Arrange
+	sampler := NewSampler(context.Background(), &MockSystemMonitor{})
+
+	// Act - publish twice without reading
+	sampler.publish(SystemStats{CPUUsage: 1.0})
+	sampler.publish(SystemStats{CPUUsage: 2.0})
+
+	// Assert - only the newest snapshot is delivered
+	stats := <-sampler.Updates()
+	if stats.CPUUsage != 2.0 {
+		t.Errorf("Expected newest snapshot with CPU 2.0, got %f", stats.CPUUsage)
+	}
+	select {
+	case extra := <-sampler.Updates():
+		t.Errorf("Expected no stale snapshot, got %+v", extra)
+	default:
+	}
+}
+
+func TestSamplerStopsWhenCancelled(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	sampler := NewSampler(ctx, &MockSystemMonitor{})
+
+	// Act
+	cancel()
+
+	// Assert - no new collections start once the app is shutting down
+	if sampler.Trigger() {
+		t.Error("Expected Trigger to refuse after the context was cancelled")
+	}
+}
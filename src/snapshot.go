@@ -0,0 +1,247 @@
+// Package main provides the snapshot subcommand for the hardware monitor.
+// This file contains a one-shot collection printed as an aligned table, JSON
+// or YAML, for scripts and tickets; the terminal UI is never started.
+//
+// The YAML writer is hand-written, like the Prometheus exporter, so the
+// monitor doesn't need a YAML library for one subcommand.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Formats accepted by snapshot --format.
+const (
+	formatTable = "table" // Aligned text, one row per value
+	formatJSON  = "json"  // The --output=jsonl snapshot, indented
+	formatYAML  = "yaml"  // The same fields as JSON
+)
+
+// runSnapshot implements "hw-monitor snapshot": it collects once with the
+// configured collectors, prints the snapshot and returns the exit code -
+// 1 if any collector failed, 2 for invalid arguments.
+func runSnapshot(args []string, stdout, stderr io.Writer, lookupEnv func(string) (string, bool)) int {
+	fs := flag.NewFlagSet("hw-monitor snapshot", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", formatTable, "output format: table, json or yaml")
+
+	cfg, err := parseConfig(fs, args, lookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return 2 // Usage was already printed
+	}
+	if err == nil && *format != formatTable && *format != formatJSON && *format != formatYAML {
+		err = fmt.Errorf("format must be %s, %s or %s, got %q", formatTable, formatJSON, formatYAML, *format)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "hw-monitor snapshot: %v\n", err)
+		return 2
+	}
+	config = cfg
+
+	monitor := NewGopsutilMonitor(Providers{})
+	return snapshot(monitor, *format, stdout, stderr)
+}
+
+// snapshot collects once - one interval after a baseline if rates are
+// enabled - and writes the result to stdout in format and every collector
+// failure to stderr.
+func snapshot(monitor SystemMonitor, format string, stdout, stderr io.Writer) int {
+	stats := fetchSettledStats(context.Background(), monitor)
+
+	var err error
+	switch format {
+	case formatJSON:
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(stats)
+	case formatYAML:
+		err = writeYAML(stdout, stats)
+	default:
+		err = writeSnapshotTable(stdout, stats)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "hw-monitor snapshot: %v\n", err)
+		return 1
+	}
+
+	// FAILURES - the values above are incomplete, so scripts must be able to tell
+	code := 0
+	for _, name := range config.Collectors {
+		if status, ok := stats.StatusOf(name); ok && status.State != StateOK {
+			fmt.Fprintf(stderr, "hw-monitor snapshot: %s %s: %s\n", name, status.State, status.Error)
+			code = 1
+		}
+	}
+	return code
+}
+
+// writeSnapshotTable writes the host, the time and every value alert rules
+// can use, one per row. Failed collectors have no rows.
+func writeSnapshotTable(w io.Writer, stats SystemStats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if stats.Host != nil {
+		fmt.Fprintf(tw, "Host:\t%s\n", hostSummary(stats.Host, stats.Time))
+	}
+	fmt.Fprintf(tw, "Time:\t%s\n\n", stats.Time.Format(time.DateOnly+" "+config.TimeFormat))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METRIC\tINSTANCE\tVALUE")
+	for _, name := range sortedKeys(alertMetrics) {
+		metric := alertMetrics[name]
+		if !stats.Succeeded(metric.collector) {
+			continue
+		}
+		for _, sample := range metric.samples(stats) {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", name, sample.instance, formatMetricValue(name, sample.value))
+		}
+	}
+	return tw.Flush()
+}
+
+// formatMetricValue renders a value with the unit its metric name implies.
+func formatMetricValue(metric string, value float64) string {
+	switch {
+	case strings.HasSuffix(metric, "_bytes_per_second"):
+		return formatRate(value)
+	case strings.HasSuffix(metric, "_percent"):
+		return fmt.Sprintf("%.*f%%", config.DecimalPlaces, value)
+	case strings.HasSuffix(metric, "_ms"):
+		return fmt.Sprintf("%.*f ms", config.DecimalPlaces, value)
+	default:
+		return fmt.Sprintf("%.*f", config.DecimalPlaces, value)
+	}
+}
+
+// yamlNode is a decoded JSON value that remembers the order of object keys,
+// so the YAML lists fields in the same order as the JSON.
+type yamlNode struct {
+	scalar   string      // Already formatted, for anything that isn't an object or array
+	keys     []string    // Object keys, in order
+	values   []*yamlNode // Object values or array items
+	isObject bool
+	isArray  bool
+}
+
+// writeYAML writes v as YAML, using its JSON encoding for field names and
+// values so both formats always agree.
+func writeYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber() // Keep numbers exactly as encoded
+	root, err := decodeYAMLNode(decoder)
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	if root.isObject || root.isArray {
+		writeYAMLBlock(&out, root, 0)
+	} else {
+		out.WriteString(root.scalar + "\n")
+	}
+	_, err = w.Write(out.Bytes())
+	return err
+}
+
+// decodeYAMLNode reads the next JSON value from decoder.
+func decodeYAMLNode(decoder *json.Decoder) (*yamlNode, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		node := &yamlNode{isObject: t == '{', isArray: t == '['}
+		for decoder.More() {
+			if node.isObject {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.keys = append(node.keys, key.(string))
+			}
+			value, err := decodeYAMLNode(decoder)
+			if err != nil {
+				return nil, err
+			}
+			node.values = append(node.values, value)
+		}
+		_, err := decoder.Token() // The closing } or ]
+		return node, err
+	case string:
+		return &yamlNode{scalar: yamlString(t)}, nil
+	case json.Number:
+		return &yamlNode{scalar: t.String()}, nil
+	case bool:
+		return &yamlNode{scalar: strconv.FormatBool(t)}, nil
+	default:
+		return &yamlNode{scalar: "null"}, nil
+	}
+}
+
+// writeYAMLBlock writes a non-empty object or array in block style at the
+// given indent. Scalars and empty collections go on the line of their key or
+// dash, anything else in a block indented below it - except that a list
+// item's block starts on the dash line, as in "- name: sda".
+func writeYAMLBlock(out *bytes.Buffer, node *yamlNode, indent int) {
+	pad := strings.Repeat(" ", indent)
+	for i, value := range node.values {
+		prefix := pad + "-"
+		if node.isObject {
+			prefix = pad + yamlString(node.keys[i]) + ":"
+		}
+
+		switch {
+		case value.isObject && len(value.values) == 0:
+			out.WriteString(prefix + " {}\n")
+		case value.isArray && len(value.values) == 0:
+			out.WriteString(prefix + " []\n")
+		case (value.isObject || value.isArray) && node.isArray:
+			var item bytes.Buffer
+			writeYAMLBlock(&item, value, indent+2)
+			out.WriteString(prefix + " ")
+			out.Write(item.Bytes()[indent+2:]) // Its first line moves up next to the dash
+		case value.isObject || value.isArray:
+			out.WriteString(prefix + "\n")
+			writeYAMLBlock(out, value, indent+2)
+		default:
+			out.WriteString(prefix + " " + value.scalar + "\n")
+		}
+	}
+}
+
+// yamlPlainPattern matches strings that YAML reads back unchanged without quotes.
+var yamlPlainPattern = regexp.MustCompile(`^[A-Za-z_/][A-Za-z0-9_./()+ -]*$`)
+
+// yamlString writes a string plainly when that is safe, and double-quoted
+// otherwise. JSON escapes are valid in double-quoted YAML.
+func yamlString(s string) string {
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "y", "n":
+		// Would be read back as a boolean or null
+	default:
+		if yamlPlainPattern.MatchString(s) && !strings.HasSuffix(s, " ") {
+			return s
+		}
+	}
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteYAML(t *testing.T) {
+	// Arrange - nesting, empty collections and strings YAML would misread
+	value := map[string]any{
+		"name":   "sda",
+		"empty":  []string{},
+		"none":   nil,
+		"quoted": []string{"yes", "12", "a: b", "", "say \"hi\""},
+		"disks": []map[string]any{
+			{"path": "/", "used": 1.5},
+			{"path": "/home", "tags": map[string]string{}},
+		},
+	}
+
+	// Act
+	var out bytes.Buffer
+	err := writeYAML(&out, value)
+
+	// Assert - keys come out in JSON order, which sorts map keys
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `disks:
+  - path: /
+    used: 1.5
+  - path: /home
+    tags: {}
+empty: []
+name: sda
+none: null
+quoted:
+  - "yes"
+  - "12"
+  - "a: b"
+  - ""
+  - "say \"hi\""
+`
+	if out.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, out.String())
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	original := config
+	defer func() { config = original }()
+	config.Collectors = []string{"cpu", "disk"}
+
+	monitor := func() *MockSystemMonitor {
+		return &MockSystemMonitor{CPUUsage: 42, DiskInfo: &DiskInfo{UsedPercent: 55}}
+	}
+
+	t.Run("Formats", func(t *testing.T) {
+		tests := []struct {
+			format string
+			want   string
+		}{
+			{format: formatTable, want: "cpu.usage_percent            42.0%"},
+			{format: formatJSON, want: "\n  \"cpu_usage\": 42,\n"},
+			{format: formatYAML, want: "\ncpu_usage: 42\n"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.format, func(t *testing.T) {
+				var stdout, stderr bytes.Buffer
+				code := snapshot(monitor(), tt.format, &stdout, &stderr)
+
+				if code != 0 || stderr.Len() != 0 {
+					t.Errorf("Expected success, got code %d and %q", code, stderr.String())
+				}
+				if !strings.Contains(stdout.String(), tt.want) {
+					t.Errorf("Expected %q in the output, got:\n%s", tt.want, stdout.String())
+				}
+			})
+		}
+	})
+
+	t.Run("CollectorFailed", func(t *testing.T) {
+		// Arrange
+		m := monitor()
+		m.DiskError = errors.New("disk unplugged")
+
+		// Act
+		var stdout, stderr bytes.Buffer
+		code := snapshot(m, formatJSON, &stdout, &stderr)
+
+		// Assert - the rest is still printed, the failure goes to stderr
+		if code != 1 {
+			t.Errorf("Expected exit code 1, got %d", code)
+		}
+		if !strings.Contains(stderr.String(), "disk error: disk unplugged") {
+			t.Errorf("Expected the disk error on stderr, got %q", stderr.String())
+		}
+		var stats SystemStats
+		if err := json.Unmarshal(stdout.Bytes(), &stats); err != nil || stats.CPUUsage != 42 {
+			t.Errorf("Expected the snapshot on stdout, got %q (%v)", stdout.String(), err)
+		}
+	})
+
+	t.Run("Rates", func(t *testing.T) {
+		// Arrange
+		config.Collectors = []string{"network"}
+		config.RefreshInterval = 20 * time.Millisecond
+		defer func() { config.Collectors = []string{"cpu", "disk"} }()
+		var calls int
+		m := NewGopsutilMonitor(Providers{Net: growingNetProvider{calls: &calls}})
+
+		// Act
+		var stdout, stderr bytes.Buffer
+		code := snapshot(m, formatJSON, &stdout, &stderr)
+
+		// Assert - the printed rate covers the interval after the baseline
+		var stats SystemStats
+		json.Unmarshal(stdout.Bytes(), &stats)
+		if code != 0 || len(stats.Network) != 1 || stats.Network[0].RxBytesPerSec == 0 {
+			t.Errorf("Expected a real receive rate, got code %d and %q (%q)", code, stdout.String(), stderr.String())
+		}
+	})
+
+	t.Run("BadFormat", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		code := runSnapshot([]string{"--format", "xml"}, &stdout, &stderr, fakeEnv(nil))
+
+		if code != 2 || stdout.Len() != 0 || !strings.Contains(stderr.String(), "format must be table, json or yaml") {
+			t.Errorf("Expected a usage error on stderr, got code %d, %q and %q", code, stdout.String(), stderr.String())
+		}
+	})
+}
@@ -4,112 +4,1043 @@ package main
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	ui "github.com/gizak/termui/v3"
 	"github.com/gizak/termui/v3/widgets"
 )
 
+// Tab indexes for the lower half of the screen.
+// The order here must match the tab names passed to widgets.NewTabPane.
+const (
+	tabInfo = iota
+	tabCores
+	tabMemory
+	tabMounts
+	tabDiskIO
+	tabProcesses
+	tabNetwork
+)
+
+// dashboard groups every widget on screen.
+// Layout and rendering functions take the dashboard instead of a growing list of widgets.
+type dashboard struct {
+	titleBar *widgets.Paragraph // One row across the top: refresh interval, pause or replay state
+
+	cpuGauge    *widgets.Gauge
+	memoryGauge *widgets.Gauge
+	swapGauge   *widgets.Gauge
+	diskGauge   *widgets.Gauge
+
+	// Recent history under each gauge, so short spikes stay visible
+	cpuHistory    *historyPanel
+	memoryHistory *historyPanel
+	swapHistory   *historyPanel
+	diskHistory   *historyPanel
+
+	// Lower half: a tab bar selecting one of the views below it
+	tabs       *widgets.TabPane
+	infoList   *widgets.List
+	coreChart  *widgets.BarChart
+	memTable   *widgets.Table
+	mountTable *widgets.Table
+	ioTable    *widgets.Table
+	procTable  *widgets.Table
+	netTable   *widgets.Table
+
+	alertBanner   *widgets.Paragraph // Active alerts, shown between the tab bar and the view
+	help          *widgets.Paragraph // Key bindings, drawn over everything while showHelp is set
+	showHelp      bool               // Toggled with ?
+	width, height int                // Terminal size of the last layout, to re-layout when the banner comes or goes
+
+	mounts      []MountInfo // Latest mounts, kept so scrolling can re-slice them
+	mountScroll int         // Index of the first mount row shown
+
+	processes  []ProcessInfo // Latest processes, re-sorted when the sort column changes
+	procScroll int           // Index of the first process row shown
+	procSort   processSort   // Column the process table is sorted by
+
+	stats       SystemStats // Latest snapshot, kept so a resize can restyle the gauges
+	blinkOn     bool        // Flips on every refresh to flash critical gauges with --critical-style blink
+	lastError   string      // Most recent collector error, kept until another one replaces it
+	lastErrorAt time.Time   // When lastError was seen
+
+	// Shown in the title bar - the app owns sampling, the dashboard only shows it
+	paused bool          // Sampling is paused
+	replay *ReplayStatus // Playback position, nil unless replaying
+}
+
+// historyPanel shows the recent samples of one metric as a sparkline.
+type historyPanel struct {
+	name   string      // Collector whose samples are recorded, e.g. "cpu"
+	gauge  string      // Key into config.GaugeThresholds, e.g. "swap"
+	label  string      // Shown in the title, e.g. "CPU"
+	buffer *RingBuffer // Last config.HistoryWindow worth of samples
+	line   *widgets.Sparkline
+	group  *widgets.SparklineGroup // The renderable widget holding line
+}
+
+// newHistoryPanel creates an empty history for the named collector,
+// colored by the thresholds of the named gauge.
+func newHistoryPanel(name, gauge, label string) *historyPanel {
+	line := widgets.NewSparkline()
+	return &historyPanel{
+		name:   name,
+		gauge:  gauge,
+		label:  label,
+		buffer: NewRingBuffer(config.historySize()),
+		line:   line,
+		group:  widgets.NewSparklineGroup(line),
+	}
+}
+
+// Colors marking a gauge whose metric couldn't be collected.
+// Together with the ERR / N/A label they keep a failure from looking like a reading.
+const (
+	errorColor = ui.ColorRed     // The collector failed
+	staleColor = ui.ColorMagenta // No fresh value (timed out, disabled, or not collected yet)
+)
+
+// levelColors colors a gauge bar by how its reading compares to its thresholds.
+var levelColors = map[AlertLevel]ui.Color{
+	LevelNone:     ui.ColorGreen,
+	LevelWarning:  ui.ColorYellow,
+	LevelCritical: ui.ColorRed,
+}
+
+// Styles accepted by --critical-style.
+// Terminals draw the label over the bar in reverse video, so the title
+// carries the emphasis too.
+const (
+	criticalBold  = "bold"  // Bold red title and label
+	criticalBlink = "blink" // Like bold, with the title flashing on every refresh
+	criticalNone  = "none"  // Just the red bar
+)
+
 // setupUI configures the initial layout of all UI components.
 // It automatically detects terminal dimensions and delegates to setupUIWithSize.
-func setupUI(cpuGauge, memoryGauge, diskGauge *widgets.Gauge, infoList *widgets.List) {
+func setupUI(d *dashboard) {
 	// Get current terminal dimensions
 	termWidth, termHeight := ui.TerminalDimensions()
 	// Delegate to the more specific function with size parameters
-	setupUIWithSize(cpuGauge, memoryGauge, diskGauge, infoList, termWidth, termHeight)
+	setupUIWithSize(d, termWidth, termHeight)
 }
 
 // setupUIWithSize configures the layout of UI components for specific dimensions.
-// It creates a responsive 2x2 grid: 3 gauges on top, info panel on bottom.
+// It creates a responsive grid: 4 gauges on top, a tab bar and the selected view on the bottom.
 // Coordinates use SetRect(x1, y1, x2, y2) where (0,0) is top-left.
-func setupUIWithSize(cpuGauge, memoryGauge, diskGauge *widgets.Gauge, infoList *widgets.List, width, height int) {
+func setupUIWithSize(d *dashboard, width, height int) {
+	d.width, d.height = width, height
+
 	// COORDINATE SYSTEM: SetRect(x1, y1, x2, y2)
 	// (0,0) is top-left corner, coordinates increase right and down
-	// We're creating a 2x2 grid: 3 gauges on top, info panel on bottom
-
-	// CPU Gauge - Left third of screen, top half
-	cpuGauge.Title = "CPU Usage"
-	cpuGauge.SetRect(0, 0, width/config.ScreenThirds, height/config.ScreenHalves) // Left third
-	cpuGauge.BarColor = ui.ColorYellow                                            // Yellow bar (warning color)
-	cpuGauge.BorderStyle.Fg = ui.ColorWhite                                       // White border
-	cpuGauge.TitleStyle.Fg = ui.ColorCyan                                         // Cyan title
-
-	// Memory Gauge - Middle third of screen, top half
-	memoryGauge.Title = "Memory Usage"
-	memoryGauge.SetRect(width/config.ScreenThirds, 0, 2*width/config.ScreenThirds, height/config.ScreenHalves) // Middle third
-	memoryGauge.BarColor = ui.ColorGreen                                                                       // Green bar (safe color)
-	memoryGauge.BorderStyle.Fg = ui.ColorWhite
-	memoryGauge.TitleStyle.Fg = ui.ColorCyan
-
-	// Disk Gauge - Right third of screen, top half
-	diskGauge.Title = "Disk Usage"
-	diskGauge.SetRect(2*width/config.ScreenThirds, 0, width, height/config.ScreenHalves) // Right third
-	diskGauge.BarColor = ui.ColorRed                                                     // Red bar (danger color)
-	diskGauge.BorderStyle.Fg = ui.ColorWhite
-	diskGauge.TitleStyle.Fg = ui.ColorCyan
-
-	// Info List - Full width, bottom half
-	infoList.Title = "System Information"
-	infoList.SetRect(0, height/config.ScreenHalves, width, height) // Full width, bottom half
-	infoList.TextStyle = ui.NewStyle(ui.ColorWhite)
-	infoList.WrapText = false // Don't wrap long lines
-	infoList.BorderStyle.Fg = ui.ColorWhite
-	infoList.TitleStyle.Fg = ui.ColorCyan
-}
-
-// updateDisplay fetches current system stats and updates all UI components.
-// It uses concurrent data fetching for optimal performance and responsiveness.
-func updateDisplay(cpuGauge, memoryGauge, diskGauge *widgets.Gauge, infoList *widgets.List, monitor SystemMonitor) {
-	// CONCURRENT DATA FETCHING - Don't block the UI!
-	// Create a channel to receive the complete system stats
-	statsCh := make(chan SystemStats, config.ChannelBuffer) // Buffered channel
-	// Start a goroutine to fetch all data concurrently
-	go fetchSystemStats(monitor, statsCh) // This runs in the background
-
-	// BLOCKING RECEIVE - Wait for the goroutine to send us data
-	stats := <-statsCh // This blocks until data arrives
+	// We're creating a 2x2 grid: 4 gauges on top, tabbed views on bottom
+
+	// Title bar - the top row. Negative padding undoes the room a Block
+	// keeps for its border, so the text fills the whole row
+	titleBottom := config.TitleBarHeight
+	d.titleBar.SetRect(0, 0, width, titleBottom)
+	d.titleBar.Border = false
+	d.titleBar.PaddingLeft, d.titleBar.PaddingTop, d.titleBar.PaddingRight, d.titleBar.PaddingBottom = -1, -1, -1, -1
+
+	// Each quarter of the top half is split again: gauge above, history below
+	topHalf := height / config.ScreenHalves
+	gaugeBottom := titleBottom + (topHalf-titleBottom)/config.ScreenHalves
+	quarter := width / config.ScreenQuarters
+
+	// CPU Gauge - First quarter of screen, top half
+	d.cpuGauge.Title = "CPU Usage"
+	d.cpuGauge.SetRect(0, titleBottom, quarter, gaugeBottom) // First quarter
+	d.cpuGauge.BorderStyle.Fg = ui.ColorWhite                // White border
+	d.cpuGauge.TitleStyle.Fg = ui.ColorCyan                  // Cyan title
+	// Bar colors follow config.GaugeThresholds - see updateGauge
+	setupHistoryPanel(d.cpuHistory, 0, gaugeBottom, quarter, topHalf)
+
+	// Memory Gauge - Second quarter of screen, top half
+	d.memoryGauge.Title = "Memory Usage"
+	d.memoryGauge.SetRect(quarter, titleBottom, 2*quarter, gaugeBottom) // Second quarter
+	d.memoryGauge.BorderStyle.Fg = ui.ColorWhite
+	d.memoryGauge.TitleStyle.Fg = ui.ColorCyan
+	setupHistoryPanel(d.memoryHistory, quarter, gaugeBottom, 2*quarter, topHalf)
+
+	// Swap Gauge - Third quarter of screen, top half
+	d.swapGauge.Title = "Swap Usage"
+	d.swapGauge.SetRect(2*quarter, titleBottom, 3*quarter, gaugeBottom) // Third quarter
+	d.swapGauge.BorderStyle.Fg = ui.ColorWhite
+	d.swapGauge.TitleStyle.Fg = ui.ColorCyan
+	setupHistoryPanel(d.swapHistory, 2*quarter, gaugeBottom, 3*quarter, topHalf)
 
+	// Disk Gauge - Last quarter of screen, top half
+	d.diskGauge.Title = "Disk Usage"
+	d.diskGauge.SetRect(3*quarter, titleBottom, width, gaugeBottom) // Last quarter, takes the rounding remainder
+	d.diskGauge.BorderStyle.Fg = ui.ColorWhite
+	d.diskGauge.TitleStyle.Fg = ui.ColorCyan
+	setupHistoryPanel(d.diskHistory, 3*quarter, gaugeBottom, width, topHalf)
+
+	// Tab bar - Full width, first rows of the bottom half
+	tabsBottom := height/config.ScreenHalves + config.TabBarHeight
+	d.tabs.SetRect(0, height/config.ScreenHalves, width, tabsBottom)
+	d.tabs.ActiveTabStyle = ui.NewStyle(ui.ColorCyan, ui.ColorClear, ui.ModifierBold)
+	d.tabs.BorderStyle.Fg = ui.ColorWhite
+
+	// Alert banner - below the tab bar, only while alerts are active
+	viewTop := tabsBottom
+	if len(d.stats.Alerts) > 0 {
+		d.alertBanner.SetRect(0, tabsBottom, width, tabsBottom+config.BannerHeight)
+		viewTop += config.BannerHeight
+	}
+
+	// Views - all share the space below the tab bar, only the active one is drawn
+	d.infoList.Title = "System Information"
+	d.infoList.SetRect(0, viewTop, width, height) // Full width, rest of bottom half
+	d.infoList.TextStyle = ui.NewStyle(ui.ColorWhite)
+	d.infoList.WrapText = false // Don't wrap long lines
+	d.infoList.BorderStyle.Fg = ui.ColorWhite
+	d.infoList.TitleStyle.Fg = ui.ColorCyan
+
+	d.coreChart.Title = "Per-Core CPU Usage"
+	d.coreChart.SetRect(0, viewTop, width, height)
+	d.coreChart.MaxVal = 100 // Percentages, so every core shares the same scale
+	d.coreChart.BarColors = []ui.Color{ui.ColorYellow}
+	d.coreChart.LabelStyles = []ui.Style{ui.NewStyle(ui.ColorWhite)}
+	d.coreChart.NumStyles = []ui.Style{ui.NewStyle(ui.ColorBlack)}
+	d.coreChart.NumFormatter = func(v float64) string { return fmt.Sprintf("%.0f", v) }
+	d.coreChart.BorderStyle.Fg = ui.ColorWhite
+	d.coreChart.TitleStyle.Fg = ui.ColorCyan
+
+	d.memTable.Title = "Memory Breakdown"
+	d.memTable.SetRect(0, viewTop, width, height)
+	d.memTable.TextStyle = ui.NewStyle(ui.ColorWhite)
+	d.memTable.RowSeparator = false
+	d.memTable.BorderStyle.Fg = ui.ColorWhite
+	d.memTable.TitleStyle.Fg = ui.ColorCyan
+	d.memTable.RowStyles[0] = ui.NewStyle(ui.ColorCyan, ui.ColorClear, ui.ModifierBold)
+
+	d.mountTable.Title = "Mounted Filesystems"
+	d.mountTable.SetRect(0, viewTop, width, height)
+	d.mountTable.TextStyle = ui.NewStyle(ui.ColorWhite)
+	d.mountTable.RowSeparator = false // One line per mount so more fit on screen
+	d.mountTable.BorderStyle.Fg = ui.ColorWhite
+	d.mountTable.TitleStyle.Fg = ui.ColorCyan
+	d.mountTable.RowStyles[0] = ui.NewStyle(ui.ColorCyan, ui.ColorClear, ui.ModifierBold) // Header row
+
+	d.ioTable.Title = "Disk I/O"
+	d.ioTable.SetRect(0, viewTop, width, height)
+	d.ioTable.TextStyle = ui.NewStyle(ui.ColorWhite)
+	d.ioTable.RowSeparator = false
+	d.ioTable.BorderStyle.Fg = ui.ColorWhite
+	d.ioTable.TitleStyle.Fg = ui.ColorCyan
+	d.ioTable.RowStyles[0] = ui.NewStyle(ui.ColorCyan, ui.ColorClear, ui.ModifierBold)
+
+	d.procTable.SetRect(0, viewTop, width, height)
+	d.procTable.TextStyle = ui.NewStyle(ui.ColorWhite)
+	d.procTable.RowSeparator = false
+	d.procTable.BorderStyle.Fg = ui.ColorWhite
+	d.procTable.TitleStyle.Fg = ui.ColorCyan
+	d.procTable.RowStyles[0] = ui.NewStyle(ui.ColorCyan, ui.ColorClear, ui.ModifierBold)
+	// Fixed columns for the numbers, the command line gets whatever is left
+	d.procTable.ColumnWidths = []int{7, 10, 7, 10, 11, 8}
+	fixed := len(d.procTable.ColumnWidths) // One separator per fixed column
+	for _, w := range d.procTable.ColumnWidths {
+		fixed += w
+	}
+	d.procTable.ColumnWidths = append(d.procTable.ColumnWidths, max(d.procTable.Inner.Dx()-fixed, 1))
+
+	d.netTable.Title = "Network Throughput"
+	d.netTable.SetRect(0, viewTop, width, height)
+	d.netTable.TextStyle = ui.NewStyle(ui.ColorWhite)
+	d.netTable.RowSeparator = false
+	d.netTable.BorderStyle.Fg = ui.ColorWhite
+	d.netTable.TitleStyle.Fg = ui.ColorCyan
+	d.netTable.RowStyles[0] = ui.NewStyle(ui.ColorCyan, ui.ColorClear, ui.ModifierBold)
+
+	// Help overlay - centered over everything else
+	d.help.Title = "Keys"
+	d.help.BorderStyle.Fg = ui.ColorCyan
+	d.help.TitleStyle = ui.NewStyle(ui.ColorCyan, ui.ColorClear, ui.ModifierBold)
+	layoutHelp(d)
+
+	// Re-fit the views that depend on their size, and restore the status colors
+	updateTitleBar(d)
+	updateAlertBanner(d)
+	updateGauges(d)
+	updateHistoryPanels(d)
+	updateCoreChart(d)
+	updateMemoryTable(d, d.stats)
+	updateMountTable(d)
+	updateDiskIOTable(d, d.stats)
+	updateNetworkTable(d, d.stats)
+	updateProcessTable(d)
+}
+
+// updateDisplay updates all UI components from a finished stats snapshot.
+// Collection already happened in the Sampler, so this never blocks.
+func updateDisplay(d *dashboard, stats SystemStats) {
 	// UPDATE GAUGES - Convert our data to visual elements
-	// Gauges expect integer percentages (0-100)
-	cpuGauge.Percent = int(stats.CPUUsage)                                       // Convert float to int
-	cpuGauge.Label = fmt.Sprintf("%.*f%%", config.DecimalPlaces, stats.CPUUsage) // Format with configured precision
+	hadAlerts := len(d.stats.Alerts) > 0
+	d.stats = stats
+	d.blinkOn = !d.blinkOn
+	updateGauges(d)
+
+	// UPDATE ALERT BANNER - it takes rows from the views, so re-layout when it comes or goes
+	if hadAlerts != (len(stats.Alerts) > 0) {
+		setupUIWithSize(d, d.width, d.height)
+		ui.Clear() // The views moved, leaving old cells behind
+	}
+	updateAlertBanner(d)
 
-	memoryGauge.Percent = int(stats.MemoryUsage)
-	memoryGauge.Label = fmt.Sprintf("%.*f%%", config.DecimalPlaces, stats.MemoryUsage)
+	// UPDATE HISTORY - only real readings are recorded, a failure leaves a gap
+	recordHistory(d.cpuHistory, stats, stats.CPUUsage)
+	recordHistory(d.memoryHistory, stats, stats.MemoryUsage)
+	recordHistory(d.swapHistory, stats, stats.SwapUsage)
+	recordHistory(d.diskHistory, stats, stats.DiskUsage)
+	updateHistoryPanels(d)
 
-	diskGauge.Percent = int(stats.DiskUsage)
-	diskGauge.Label = fmt.Sprintf("%.*f%%", config.DecimalPlaces, stats.DiskUsage)
+	// Remember the newest error so it stays readable after the metric recovers
+	if message := stats.LastError(); message != "" {
+		d.lastError = message
+		d.lastErrorAt = stats.Time
+	}
 
 	// UPDATE INFO LIST - Create detailed text information
 	// infoList.Rows is a slice of strings (like an array but dynamic)
-	infoList.Rows = []string{
-		fmt.Sprintf("Time: %s", time.Now().Format(config.TimeFormat)),
+	now := stats.Time // The recorded time when replaying
+	if now.IsZero() {
+		now = time.Now()
+	}
+	d.infoList.Rows = []string{
+		fmt.Sprintf("Time: %s", now.Format(config.TimeFormat)),
+		"Host: " + metricText(stats, "host", hostSummary(stats.Host, now)),
+		"Processor: " + metricText(stats, "host", processorSummary(stats.Host)),
+		"Load: " + metricText(stats, "host", loadSummary(stats.Host)),
 		"", // Empty line for spacing
-		fmt.Sprintf("CPU: %.*f%%", config.DecimalPlaces, stats.CPUUsage),
-		"",
-		fmt.Sprintf("Memory: %.*f%% (%.*f GB / %.*f GB)",
-			config.DecimalPlaces, stats.MemoryUsage, config.DecimalPlaces, stats.MemoryUsed, config.DecimalPlaces, stats.MemoryTotal),
-		"",
-		fmt.Sprintf("Disk (%s): %.*f%% (%.*f GB / %.*f GB)",
-			config.DiskDrive, config.DecimalPlaces, stats.DiskUsage, config.DecimalPlaces, stats.DiskUsed, config.DecimalPlaces, stats.DiskTotal),
+		"CPU: " + metricText(stats, "cpu", fmt.Sprintf("%.*f%%", config.DecimalPlaces, stats.CPUUsage)),
+		"Memory: " + metricText(stats, "memory", fmt.Sprintf("%.*f%% (%.*f GB / %.*f GB), swap %s",
+			config.DecimalPlaces, stats.MemoryUsage, config.DecimalPlaces, stats.MemoryUsed, config.DecimalPlaces, stats.MemoryTotal, swapText(stats))),
+		fmt.Sprintf("Disk (%s): ", config.DiskDrive) + metricText(stats, "disk", fmt.Sprintf("%.*f%% (%.*f GB / %.*f GB)",
+			config.DecimalPlaces, stats.DiskUsage, config.DecimalPlaces, stats.DiskUsed, config.DecimalPlaces, stats.DiskTotal)),
+		"Disk I/O: " + metricText(stats, "diskio", diskIOSummary(stats.DiskIO)),
+		"Network: " + metricText(stats, "network", networkSummary(stats.Network)),
 		"",
-		"Press 'q' or Ctrl+C to quit", // User instruction
 	}
+	if d.lastError != "" {
+		d.infoList.Rows = append(d.infoList.Rows,
+			fmt.Sprintf("Last error (%s): %s", d.lastErrorAt.Format(config.TimeFormat), d.lastError),
+			"")
+	}
+	d.infoList.Rows = append(d.infoList.Rows,
+		"Press 'q' or Ctrl+C to quit, Tab or Left/Right to switch views, ? for all keys") // User instruction
+
+	// UPDATE CORE CHART - One bar per logical CPU
+	d.coreChart.Data = stats.CoreUsage
+	updateCoreChart(d)
+
+	// UPDATE MEMORY TABLE - Where the memory actually goes
+	updateMemoryTable(d, stats)
+
+	// UPDATE MOUNT TABLE - Keep the full list so scrolling works between ticks
+	d.mounts = stats.Mounts
+	updateMountTable(d)
+
+	// UPDATE DISK I/O TABLE - One row per block device
+	updateDiskIOTable(d, stats)
+
+	// UPDATE PROCESS TABLE - Sorted by the column the user picked
+	d.processes = stats.Processes
+	updateProcessTable(d)
+
+	// UPDATE NETWORK TABLE - One row per interface
+	updateNetworkTable(d, stats)
 
 	// RENDER - Actually draw everything to the screen
 	// This is when the user sees the updated information
-	ui.Render(cpuGauge, memoryGauge, diskGauge, infoList)
+	renderDashboard(d)
+}
+
+// updateGauges shows the latest values on the gauges, or ERR / N/A in a
+// distinct color when a metric couldn't be collected.
+func updateGauges(d *dashboard) {
+	if d.stats.Status == nil {
+		return // No snapshot yet - keep the empty gauges
+	}
+	updateGauge(d.cpuGauge, d.stats, "cpu", d.stats.CPUUsage, config.GaugeThresholds["cpu"], d.blinkOn)
+	updateGauge(d.memoryGauge, d.stats, "memory", d.stats.MemoryUsage, config.GaugeThresholds["memory"], d.blinkOn)
+	updateGauge(d.swapGauge, d.stats, "memory", d.stats.SwapUsage, config.GaugeThresholds["swap"], d.blinkOn) // Swap is read by the memory collector
+	updateGauge(d.diskGauge, d.stats, "disk", d.stats.DiskUsage, config.GaugeThresholds["disk"], d.blinkOn)
+
+	// 0% would suggest swap exists but is idle
+	if d.stats.Succeeded("memory") && d.stats.SwapTotal == 0 {
+		d.swapGauge.Label = "No swap"
+	}
+}
+
+// updateGauge sets one gauge from the named collector's value and status,
+// colored by the gauge's thresholds. blinkOn alternates between refreshes.
+func updateGauge(g *widgets.Gauge, stats SystemStats, name string, value float64, threshold GaugeThreshold, blinkOn bool) {
+	status, ok := stats.StatusOf(name)
+
+	// Styles start from the normal look and are overridden for failures
+	color := ui.ColorWhite
+	critical := false
+	switch {
+	case ok && status.State == StateOK:
+		// Gauges expect integer percentages (0-100)
+		g.Percent = int(value)                                       // Convert float to int
+		g.Label = fmt.Sprintf("%.*f%%", config.DecimalPlaces, value) // Format with configured precision
+
+		// COLOR BY THRESHOLD - recomputed every tick as the value moves
+		level := threshold.level(value)
+		g.BarColor = levelColors[level]
+		critical = level == LevelCritical && config.CriticalStyle != criticalNone
+	case ok && status.State == StateError:
+		g.Percent = 0
+		g.Label = "ERR"
+		color = errorColor
+	default:
+		g.Percent = 0
+		g.Label = "N/A"
+		color = staleColor
+	}
+
+	g.BorderStyle.Fg = color
+	g.TitleStyle = ui.NewStyle(ui.ColorCyan)
+	g.LabelStyle = ui.NewStyle(ui.ColorWhite)
+	if color != ui.ColorWhite {
+		g.LabelStyle = ui.NewStyle(color, ui.ColorClear, ui.ModifierBold)
+	}
+
+	// CRITICAL EMPHASIS - bold red title and label, flashing with --critical-style blink
+	if critical {
+		g.TitleStyle = ui.NewStyle(ui.ColorRed, ui.ColorClear, ui.ModifierBold)
+		g.LabelStyle = ui.NewStyle(ui.ColorRed, ui.ColorClear, ui.ModifierBold)
+		if config.CriticalStyle == criticalBlink && blinkOn {
+			g.TitleStyle.Modifier |= ui.ModifierReverse
+		}
+	}
+}
+
+// metricText returns text for the info list, or why the named metric is missing.
+func metricText(stats SystemStats, name, text string) string {
+	status, ok := stats.StatusOf(name)
+	switch {
+	case !ok:
+		return "N/A (collector disabled)"
+	case status.State == StateError:
+		return "ERR - " + status.Error
+	case status.State == StateStale, status.State == StatePending:
+		return "N/A - " + status.Error
+	}
+	return text
+}
+
+// setupHistoryPanel positions and styles a history sparkline.
+// Line colors follow config.GaugeThresholds - see updateHistoryPanel.
+func setupHistoryPanel(p *historyPanel, x1, y1, x2, y2 int) {
+	p.group.SetRect(x1, y1, x2, y2)
+	p.group.BorderStyle.Fg = ui.ColorWhite
+	p.group.TitleStyle.Fg = ui.ColorCyan
+	p.line.MaxVal = 100 // Percentages, so a flat line means the same in every panel
+}
+
+// recordHistory adds the panel's value if its collector succeeded.
+func recordHistory(p *historyPanel, stats SystemStats, value float64) {
+	if stats.Succeeded(p.name) {
+		p.buffer.Add(value)
+	}
+}
+
+// updateHistoryPanels fits every history into its sparkline.
+func updateHistoryPanels(d *dashboard) {
+	for _, p := range []*historyPanel{d.cpuHistory, d.memoryHistory, d.swapHistory, d.diskHistory} {
+		updateHistoryPanel(p)
+	}
+}
+
+// resizeHistories keeps every history covering config.HistoryWindow after
+// the refresh interval changed.
+func resizeHistories(d *dashboard) {
+	for _, p := range []*historyPanel{d.cpuHistory, d.memoryHistory, d.swapHistory, d.diskHistory} {
+		p.buffer.Resize(config.historySize())
+	}
+	updateHistoryPanels(d)
+}
+
+// updateHistoryPanel squeezes the whole window into the sparkline's width,
+// keeping each column's peak, and names the overall peak in the title.
+// The line is colored like the gauge would be at that peak.
+func updateHistoryPanel(p *historyPanel) {
+	if p.buffer.Len() == 0 {
+		p.line.Data = nil
+		p.line.LineColor = ui.ColorWhite
+		p.group.Title = p.label + " History (no data)"
+		return
+	}
+	p.line.Data = downsampleMax(p.buffer.Values(), p.group.Inner.Dx())
+	p.line.LineColor = levelColors[config.GaugeThresholds[p.gauge].level(p.buffer.Max())]
+	// Kept short - four panels share the width
+	p.group.Title = fmt.Sprintf("%s %s, peak %.*f%%",
+		p.label, shortDuration(config.HistoryWindow), config.DecimalPlaces, p.buffer.Max())
+}
+
+// shortDuration formats whole minutes as "5m" instead of Go's "5m0s".
+func shortDuration(d time.Duration) string {
+	if d >= time.Minute && d%time.Minute == 0 {
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return d.String()
+}
+
+// renderDashboard draws the title bar, the gauges, the tab bar and the
+// active view, plus the help overlay when it is shown.
+func renderDashboard(d *dashboard) {
+	ui.Render(d.titleBar, d.cpuGauge, d.memoryGauge, d.swapGauge, d.diskGauge, d.tabs)
+	ui.Render(d.cpuHistory.group, d.memoryHistory.group, d.swapHistory.group, d.diskHistory.group)
+	if len(d.stats.Alerts) > 0 {
+		ui.Render(d.alertBanner)
+	}
+
+	ui.Render(activeView(d))
+
+	// Last, so it covers whatever is below it
+	if d.showHelp {
+		ui.Render(d.help)
+	}
+}
+
+// activeView returns the view of the selected tab.
+func activeView(d *dashboard) ui.Drawable {
+	switch d.tabs.ActiveTabIndex {
+	case tabCores:
+		return d.coreChart
+	case tabMemory:
+		return d.memTable
+	case tabMounts:
+		return d.mountTable
+	case tabDiskIO:
+		return d.ioTable
+	case tabProcesses:
+		return d.procTable
+	case tabNetwork:
+		return d.netTable
+	}
+	return d.infoList
+}
+
+// updateTitleBar shows the refresh interval and whether sampling is paused,
+// or where a replay is.
+func updateTitleBar(d *dashboard) {
+	d.titleBar.Text = titleText(d)
+}
+
+// titleText builds the title bar text, using termui's [text](style) markup.
+func titleText(d *dashboard) string {
+	parts := []string{fmt.Sprintf("[%s](fg:cyan,mod:bold)", config.Title)}
+	switch {
+	case d.replay != nil:
+		state := "paused"
+		if d.replay.Playing {
+			state = "playing"
+		}
+		parts = append(parts, fmt.Sprintf("[Replay %s](fg:yellow,mod:bold) (%s of %s), %gx, %s",
+			d.replay.Time.Format(time.DateOnly+" "+config.TimeFormat),
+			d.replay.Elapsed.Round(time.Second), d.replay.Length.Round(time.Second),
+			d.replay.Speed, state))
+	case d.paused:
+		parts = append(parts, "[PAUSED](fg:yellow,mod:bold) - p to resume")
+	}
+	parts = append(parts, "refresh every "+shortDuration(config.RefreshInterval), "? for help, q to quit")
+	return strings.Join(parts, " | ")
+}
+
+// keyBinding is one line of the help overlay.
+type keyBinding struct {
+	keys   string
+	action string
+}
+
+// keyBindings lists every key the dashboard understands.
+var keyBindings = []keyBinding{
+	{"q, Ctrl+C", "Quit"},
+	{"Tab, Left/Right, h/l", "Switch views"},
+	{"Up/Down, j/k", "Scroll the table"},
+	{"PageUp/PageDown", "Scroll a page"},
+	{"s", "Sort processes by CPU, memory, I/O or PID"},
+	{"p", "Pause or resume sampling"},
+	{"+ / -", "Longer or shorter refresh interval"},
+	{"r", "Refresh now"},
+	{"?", "Show or hide this help"},
+}
+
+// replayKeyBindings are added to the help while replaying.
+var replayKeyBindings = []keyBinding{
+	{"Space, p", "Pause or resume playback"},
+	{"> / <", "Play faster or slower"},
+	{"f / b", "Jump forwards or backwards"},
+}
+
+// helpLines returns the lines of the help overlay.
+func helpLines(replay bool) []string {
+	bindings := keyBindings
+	if replay {
+		bindings = append(slices.Clone(keyBindings), replayKeyBindings...)
+	}
+	lines := make([]string, 0, len(bindings))
+	for _, b := range bindings {
+		lines = append(lines, fmt.Sprintf(" %-22s %s", b.keys, b.action))
+	}
+	return lines
+}
+
+// toggleHelp shows or hides the help overlay.
+func toggleHelp(d *dashboard) {
+	d.showHelp = !d.showHelp
+	if d.showHelp {
+		layoutHelp(d)
+	} else {
+		ui.Clear() // Uncover what was below it
+	}
+}
+
+// layoutHelp fills in the help overlay and centers it on the screen.
+func layoutHelp(d *dashboard) {
+	lines := helpLines(d.replay != nil)
+	d.help.Text = strings.Join(lines, "\n")
+	d.help.WrapText = false
+
+	// Fit the longest line, plus the border and a space on either side
+	width := 0
+	for _, line := range lines {
+		width = max(width, len(line))
+	}
+	width = min(width+4, d.width)
+	height := min(len(lines)+2, d.height)
+	x, y := (d.width-width)/2, (d.height-height)/2
+	d.help.SetRect(x, y, x+width, y+height)
+}
+
+// updateAlertBanner lists the active alerts, most severe first, in the
+// color of the most severe one.
+func updateAlertBanner(d *dashboard) {
+	alerts := d.stats.Alerts
+	if len(alerts) == 0 {
+		return
+	}
+
+	texts := make([]string, 0, len(alerts))
+	for _, a := range alerts {
+		texts = append(texts, alertText(a))
+	}
+	d.alertBanner.Text = strings.Join(texts, "  |  ") // Whatever doesn't fit is cut off; the title has the count
+
+	color := ui.ColorYellow
+	if alerts[0].Level == LevelCritical {
+		color = ui.ColorRed
+	}
+	d.alertBanner.Title = fmt.Sprintf("%d active alert(s)", len(alerts))
+	d.alertBanner.BorderStyle.Fg = color
+	d.alertBanner.TitleStyle = ui.NewStyle(color, ui.ColorClear, ui.ModifierBold)
+	d.alertBanner.TextStyle = ui.NewStyle(color, ui.ColorClear, ui.ModifierBold)
+}
+
+// alertText describes one alert, e.g. "CRITICAL cpu.usage_percent = 97.2 (threshold 95) since 10:04:05".
+func alertText(a Alert) string {
+	return fmt.Sprintf("%s %s = %.*f (threshold %s) since %s",
+		strings.ToUpper(a.Level.String()), a.Name(), config.DecimalPlaces, a.Value,
+		formatThreshold(a.Threshold), a.Since.Format(config.TimeFormat))
+}
+
+// switchTab moves the active tab left (delta < 0) or right (delta > 0), wrapping around.
+func switchTab(d *dashboard, delta int) {
+	count := len(d.tabs.TabNames)
+	d.tabs.ActiveTabIndex = ((d.tabs.ActiveTabIndex+delta)%count + count) % count
+}
+
+// scrollActiveView scrolls the active view by delta rows.
+// Views that fit on screen ignore scrolling.
+func scrollActiveView(d *dashboard, delta int) {
+	switch d.tabs.ActiveTabIndex {
+	case tabMounts:
+		d.mountScroll += delta
+		updateMountTable(d)
+	case tabProcesses:
+		d.procScroll += delta
+		updateProcessTable(d)
+	}
+}
+
+// cycleProcessSort sorts the process table by the next column and
+// returns to the top, where the busiest processes are.
+func cycleProcessSort(d *dashboard) {
+	d.procSort = (d.procSort + 1) % processSortCount
+	d.procScroll = 0
+	updateProcessTable(d)
+}
+
+// updateCoreChart sizes the bars so every core fits across the chart and
+// names the busiest core in the title, since that's what the aggregate hides.
+func updateCoreChart(d *dashboard) {
+	cores := len(d.coreChart.Data)
+	if cores == 0 {
+		d.coreChart.Title = "Per-Core CPU Usage (no data)"
+		return
+	}
+
+	// Labels are core numbers, matching the order gopsutil reports them in
+	if len(d.coreChart.Labels) != cores {
+		d.coreChart.Labels = make([]string, cores)
+		for i := range d.coreChart.Labels {
+			d.coreChart.Labels[i] = fmt.Sprint(i)
+		}
+	}
+
+	// Give each core an equal slice of the width, dropping the gap when space is tight
+	slot := min(d.coreChart.Inner.Dx()/cores, config.MaxBarWidth)
+	d.coreChart.BarGap = 1
+	if slot < 3 {
+		d.coreChart.BarGap = 0
+	}
+	d.coreChart.BarWidth = max(slot-d.coreChart.BarGap, 1)
+
+	busiest := 0
+	for i, usage := range d.coreChart.Data {
+		if usage > d.coreChart.Data[busiest] {
+			busiest = i
+		}
+	}
+	d.coreChart.Title = fmt.Sprintf("Per-Core CPU Usage (%d cores, busiest: #%d at %.*f%%)",
+		cores, busiest, config.DecimalPlaces, d.coreChart.Data[busiest])
+}
+
+// updateMountTable fills the mount table with the rows that fit on screen,
+// starting at the current scroll position.
+func updateMountTable(d *dashboard) {
+	header := []string{"Mount", "Device", "Type", "Used", "Total", "Use%"}
+
+	if !containsFold(config.Collectors, "mounts") {
+		d.mountTable.Rows = [][]string{header, {"Run with --all-disks to list mounted filesystems", "", "", "", "", ""}}
+		return
+	}
+
+	// Rows available inside the border, minus the header row
+	visible := d.mountTable.Inner.Dy() - 1
+	if visible < 1 {
+		visible = 1
+	}
+
+	// Clamp the scroll position so the last page stays full
+	maxScroll := len(d.mounts) - visible
+	if maxScroll < 0 {
+		maxScroll = 0
+	}
+	if d.mountScroll > maxScroll {
+		d.mountScroll = maxScroll
+	}
+	if d.mountScroll < 0 {
+		d.mountScroll = 0
+	}
+
+	rows := [][]string{header}
+	end := d.mountScroll + visible
+	if end > len(d.mounts) {
+		end = len(d.mounts)
+	}
+	for _, m := range d.mounts[d.mountScroll:end] {
+		rows = append(rows, []string{
+			m.Path,
+			m.Device,
+			m.Fstype,
+			formatBytes(m.Used),
+			formatBytes(m.Total),
+			fmt.Sprintf("%.*f%%", config.DecimalPlaces, m.UsedPercent),
+		})
+	}
+	d.mountTable.Rows = rows
+	d.mountTable.Title = fmt.Sprintf("Mounted Filesystems (%d-%d of %d)", min(d.mountScroll+1, end), end, len(d.mounts))
+}
+
+// updateProcessTable fills the process table with the rows that fit on screen,
+// sorted by the selected column and starting at the current scroll position.
+func updateProcessTable(d *dashboard) {
+	header := []string{"PID", "User", "CPU%", "Memory", "I/O", "State", "Command"}
+	// Mark the sort column in the header
+	column := map[processSort]int{sortByPID: 0, sortByCPU: 2, sortByMemory: 3, sortByIO: 4}[d.procSort]
+	header[column] += " ▼"
+
+	if !containsFold(config.Collectors, "processes") {
+		d.procTable.Rows = [][]string{header, {"", "", "", "", "", "", "Add processes to --collectors to list processes"}}
+		return
+	}
+
+	// Rows available inside the border, minus the header row
+	visible := max(d.procTable.Inner.Dy()-1, 1)
+	d.procScroll = max(min(d.procScroll, len(d.processes)-visible), 0)
+	end := min(d.procScroll+visible, len(d.processes))
+
+	// Sort a copy so the snapshot order stays as collected
+	procs := append([]ProcessInfo(nil), d.processes...)
+	sortProcesses(procs, d.procSort)
+
+	rows := [][]string{header}
+	for _, p := range procs[d.procScroll:end] {
+		rows = append(rows, []string{
+			fmt.Sprint(p.PID),
+			p.User,
+			fmt.Sprintf("%.*f", config.DecimalPlaces, p.CPUPercent),
+			formatBytes(p.RSS),
+			formatRate(p.IORate),
+			p.State,
+			p.Command,
+		})
+	}
+	d.procTable.Rows = rows
+	d.procTable.Title = fmt.Sprintf("Processes (%d-%d of %d, sorted by %s - 's' to change)",
+		min(d.procScroll+1, end), end, len(d.processes), d.procSort)
+}
+
+// hostSummary names the machine, e.g. "web1 - ubuntu 24.04, kernel 6.8.0, up 3d 4h 12m".
+func hostSummary(h *HostInfo, now time.Time) string {
+	if h == nil {
+		return ""
+	}
+	summary := fmt.Sprintf("%s - %s", h.Hostname, h.OS)
+	if h.Kernel != "" {
+		summary += ", kernel " + h.Kernel
+	}
+	if uptime := h.Uptime(now); uptime > 0 {
+		summary += ", up " + formatUptime(uptime)
+	}
+	return summary
+}
+
+// processorSummary describes the CPU, e.g. "AMD Ryzen 7 7840U (8 cores, 16 threads)".
+func processorSummary(h *HostInfo) string {
+	if h == nil {
+		return ""
+	}
+	model := h.CPUModel
+	if model == "" {
+		model = "unknown model"
+	}
+	if h.PhysicalCores > 0 {
+		return fmt.Sprintf("%s (%d cores, %d threads)", model, h.PhysicalCores, h.LogicalCores)
+	}
+	return fmt.Sprintf("%s (%d threads)", model, h.LogicalCores)
+}
+
+// loadSummary shows the 1, 5 and 15 minute load averages and how much of the
+// machine they represent, e.g. "2.00, 1.00, 0.50 (50%, 25%, 12% of 4 threads)".
+func loadSummary(h *HostInfo) string {
+	if h == nil {
+		return ""
+	}
+	summary := fmt.Sprintf("%.2f, %.2f, %.2f", h.Load1, h.Load5, h.Load15)
+	if h.LogicalCores > 0 {
+		summary += fmt.Sprintf(" (%.0f%%, %.0f%%, %.0f%% of %d threads)",
+			h.LoadPercent(h.Load1), h.LoadPercent(h.Load5), h.LoadPercent(h.Load15), h.LogicalCores)
+	}
+	return summary
+}
+
+// formatUptime renders a duration in days, hours and minutes, e.g. "3d 4h 12m".
+func formatUptime(d time.Duration) string {
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
+
+// updateMemoryTable breaks memory down by use. On Linux "used" leaves out the
+// page cache, so the cache and available rows show how much is really free.
+func updateMemoryTable(d *dashboard, stats SystemStats) {
+	header := []string{"Memory", "Size", "Share"}
+
+	// termui can't draw a table without rows, and the first snapshot may take a while
+	if stats.Time.IsZero() {
+		d.memTable.Rows = [][]string{header, {"Collecting system data...", "", ""}}
+		return
+	}
+	if !stats.Succeeded("memory") || stats.MemoryDetail == nil {
+		d.memTable.Rows = [][]string{header, {metricText(stats, "memory", "No data"), "", ""}}
+		return
+	}
+
+	m := stats.MemoryDetail
+	row := func(name string, bytes, total uint64) []string {
+		share := ""
+		if total > 0 {
+			share = fmt.Sprintf("%.*f%%", config.DecimalPlaces, float64(bytes)/float64(total)*100)
+		}
+		return []string{name, formatBytes(bytes), share}
+	}
+	d.memTable.Rows = [][]string{
+		header,
+		row("Total", m.Total, m.Total),
+		row("Used", m.Used, m.Total),
+		row("Available", m.Available, m.Total),
+		row("Cached", m.Cached, m.Total),
+		row("Buffers", m.Buffers, m.Total),
+		row("Shared", m.Shared, m.Total),
+		row("Dirty", m.Dirty, m.Total),
+		row("Slab", m.Slab, m.Total),
+		row("Swap total", m.SwapTotal, m.SwapTotal),
+		row("Swap used", m.SwapUsed, m.SwapTotal),
+	}
+}
+
+// swapText describes swap usage for the info list, e.g. "12.5% (0.5 GB / 4.0 GB)".
+func swapText(stats SystemStats) string {
+	if stats.SwapTotal == 0 {
+		return "not configured"
+	}
+	return fmt.Sprintf("%.*f%% (%.*f GB / %.*f GB)",
+		config.DecimalPlaces, stats.SwapUsage, config.DecimalPlaces, stats.SwapUsed, config.DecimalPlaces, stats.SwapTotal)
+}
+
+// updateDiskIOTable lists the I/O activity of every block device.
+func updateDiskIOTable(d *dashboard, stats SystemStats) {
+	header := []string{"Device", "Read", "Write", "Read IOPS", "Write IOPS", "Util", "Await"}
+
+	if !containsFold(config.Collectors, "diskio") {
+		d.ioTable.Rows = [][]string{header, {"Add diskio to --collectors to show disk I/O", "", "", "", "", "", ""}}
+		return
+	}
+	if stats.Time.IsZero() {
+		d.ioTable.Rows = [][]string{header, {"Collecting system data...", "", "", "", "", "", ""}}
+		return
+	}
+
+	rows := [][]string{header}
+	for _, io := range stats.DiskIO {
+		rows = append(rows, []string{
+			io.Name,
+			formatRate(io.ReadBytesPerSec),
+			formatRate(io.WriteBytesPerSec),
+			fmt.Sprintf("%.*f/s", config.DecimalPlaces, io.ReadIOPS),
+			fmt.Sprintf("%.*f/s", config.DecimalPlaces, io.WriteIOPS),
+			fmt.Sprintf("%.*f%%", config.DecimalPlaces, io.UtilPercent),
+			fmt.Sprintf("%.*f ms", config.DecimalPlaces, io.AwaitMs),
+		})
+	}
+	d.ioTable.Rows = rows
+	d.ioTable.Title = "Disk I/O (" + metricText(stats, "diskio", diskIOSummary(stats.DiskIO)) + ")"
+}
+
+// diskIOSummary totals the throughput of every device and names the busiest,
+// e.g. "read 1.2 MiB/s, write 300.0 KiB/s, busiest sda at 12.5%".
+// A partition's I/O is also counted on its disk, so the totals can overstate it.
+func diskIOSummary(devices []DiskIOInfo) string {
+	var read, write float64
+	busiest := -1
+	for i, io := range devices {
+		read += io.ReadBytesPerSec
+		write += io.WriteBytesPerSec
+		if busiest < 0 || io.UtilPercent > devices[busiest].UtilPercent {
+			busiest = i
+		}
+	}
+	summary := fmt.Sprintf("read %s, write %s", formatRate(read), formatRate(write))
+	if busiest >= 0 {
+		summary += fmt.Sprintf(", busiest %s at %.*f%%", devices[busiest].Name, config.DecimalPlaces, devices[busiest].UtilPercent)
+	}
+	return summary
+}
+
+// updateNetworkTable lists the throughput of every interface.
+func updateNetworkTable(d *dashboard, stats SystemStats) {
+	header := []string{"Interface", "Receive", "Transmit", "Packets In", "Packets Out", "Errors In/Out", "Drops In/Out"}
+
+	if !containsFold(config.Collectors, "network") {
+		d.netTable.Rows = [][]string{header, {"Add network to --collectors to show throughput", "", "", "", "", "", ""}}
+		return
+	}
+	if stats.Time.IsZero() {
+		d.netTable.Rows = [][]string{header, {"Collecting system data...", "", "", "", "", "", ""}}
+		return
+	}
+
+	rows := [][]string{header}
+	for _, n := range stats.Network {
+		rows = append(rows, []string{
+			n.Name,
+			formatRate(n.RxBytesPerSec),
+			formatRate(n.TxBytesPerSec),
+			fmt.Sprintf("%.*f/s", config.DecimalPlaces, n.RxPacketsPerSec),
+			fmt.Sprintf("%.*f/s", config.DecimalPlaces, n.TxPacketsPerSec),
+			fmt.Sprintf("%d / %d", n.RxErrors, n.TxErrors),
+			fmt.Sprintf("%d / %d", n.RxDrops, n.TxDrops),
+		})
+	}
+	d.netTable.Rows = rows
+	d.netTable.Title = "Network Throughput (" + metricText(stats, "network", networkSummary(stats.Network)) + ")"
+}
+
+// networkSummary totals the throughput of every interface, e.g. "in 1.2 MiB/s, out 30.0 KiB/s".
+func networkSummary(interfaces []NetInterfaceInfo) string {
+	var rx, tx float64
+	for _, n := range interfaces {
+		rx += n.RxBytesPerSec
+		tx += n.TxBytesPerSec
+	}
+	return fmt.Sprintf("in %s, out %s", formatRate(rx), formatRate(tx))
+}
+
+// formatRate renders a bytes-per-second rate with an automatically chosen unit.
+func formatRate(bytesPerSec float64) string {
+	return formatBytes(uint64(bytesPerSec)) + "/s"
+}
+
+// formatBytes renders a byte count with an automatically chosen binary unit.
+func formatBytes(bytes uint64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+	value := float64(bytes)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%.*f %s", config.DecimalPlaces, value, units[unit])
 }
 
 // createWidgets creates and returns all the UI widgets needed for the application.
 // This is a factory function that centralizes widget creation.
-func createWidgets() (*widgets.Gauge, *widgets.Gauge, *widgets.Gauge, *widgets.List) {
+func createWidgets() *dashboard {
 	// Create UI components (widgets) - these are like building blocks
 	// widgets.NewGauge() returns a pointer to a new Gauge widget
-	cpuGauge := widgets.NewGauge()    // Visual progress bar for CPU
-	memoryGauge := widgets.NewGauge() // Visual progress bar for Memory
-	diskGauge := widgets.NewGauge()   // Visual progress bar for Disk
-	infoList := widgets.NewList()     // Text list for detailed information
+	d := &dashboard{
+		cpuGauge:    widgets.NewGauge(), // Visual progress bar for CPU
+		memoryGauge: widgets.NewGauge(), // Visual progress bar for Memory
+		swapGauge:   widgets.NewGauge(), // Visual progress bar for Swap
+		diskGauge:   widgets.NewGauge(), // Visual progress bar for Disk
+
+		// Sparklines of recent values under each gauge
+		cpuHistory:    newHistoryPanel("cpu", "cpu", "CPU"),
+		memoryHistory: newHistoryPanel("memory", "memory", "Memory"),
+		swapHistory:   newHistoryPanel("memory", "swap", "Swap"),
+		diskHistory:   newHistoryPanel("disk", "disk", "Disk"),
+
+		tabs:       widgets.NewTabPane("Info", "Cores", "Memory", "Mounts", "Disk I/O", "Processes", "Network"), // Selects the view in the bottom half
+		infoList:   widgets.NewList(),                                                                           // Text list for detailed information
+		coreChart:  widgets.NewBarChart(),                                                                       // One bar per logical CPU
+		memTable:   widgets.NewTable(),                                                                          // Memory breakdown: cache, buffers, swap and more
+		mountTable: widgets.NewTable(),                                                                          // Scrollable table of mounted filesystems
+		ioTable:    widgets.NewTable(),                                                                          // Throughput, IOPS and latency per block device
+		procTable:  widgets.NewTable(),                                                                          // Sortable table of the busiest processes
+		netTable:   widgets.NewTable(),                                                                          // Throughput per network interface
+
+		titleBar:    widgets.NewParagraph(), // Refresh interval and pause state
+		alertBanner: widgets.NewParagraph(), // Shown only while alerts are active
+		help:        widgets.NewParagraph(), // Key bindings, toggled with ?
+	}
 
-	return cpuGauge, memoryGauge, diskGauge, infoList
+	// Shown until the sampler delivers the first snapshot
+	d.infoList.Rows = []string{"Collecting system data..."}
+	return d
 }
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	ui "github.com/gizak/termui/v3"
+)
+
+func TestDashboardBeforeFirstSnapshot(t *testing.T) {
+	// Arrange - laid out, but the sampler hasn't delivered anything yet
+	d := createWidgets()
+	setupUIWithSize(d, 120, 40)
+
+	for tab, name := range d.tabs.TabNames {
+		t.Run(name, func(t *testing.T) {
+			// Act - termui panics on tables without rows
+			d.tabs.ActiveTabIndex = tab
+			view := activeView(d)
+			err := func() (err error) {
+				defer func() {
+					if r := recover(); r != nil {
+						err = fmt.Errorf("%v", r)
+					}
+				}()
+				view.Draw(ui.NewBuffer(view.GetRect()))
+				return nil
+			}()
+
+			// Assert
+			if err != nil {
+				t.Errorf("Expected the view to draw, got panic: %v", err)
+			}
+		})
+	}
+}
+
+func TestHistoryPanelColor(t *testing.T) {
+	// Disk defaults to 85/95: a mostly empty disk must not look alarming
+	tests := []struct {
+		name   string
+		values []float64
+		color  ui.Color
+	}{
+		{name: "NoData", values: nil, color: ui.ColorWhite},
+		{name: "Normal", values: []float64{5, 6}, color: ui.ColorGreen},
+		{name: "Warning", values: []float64{5, 90, 6}, color: ui.ColorYellow},
+		{name: "Critical", values: []float64{97}, color: ui.ColorRed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			p := newHistoryPanel("disk", "disk", "Disk")
+			setupHistoryPanel(p, 0, 0, 40, 10)
+			for _, v := range tt.values {
+				p.buffer.Add(v)
+			}
+
+			// Act
+			updateHistoryPanel(p)
+
+			// Assert - colored by the peak, like the gauge would be
+			if p.line.LineColor != tt.color {
+				t.Errorf("Expected color %v, got %v", tt.color, p.line.LineColor)
+			}
+		})
+	}
+}
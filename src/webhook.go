@@ -0,0 +1,276 @@
+// Package main provides webhook notifications for the hardware monitor.
+// This file contains the WebhookNotifier, which POSTs alerts that fire,
+// change level or resolve to the URLs given with --webhooks.
+//
+// Deliveries happen in the background: every URL has its own bounded queue
+// and goroutine, so a slow or unreachable endpoint never holds up sampling,
+// the display, or the other endpoints.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Notification statuses, one per kind of payload.
+const (
+	notifyFiring   = "firing"   // Alerts that fired or changed level
+	notifyResolved = "resolved" // Alerts that cleared
+)
+
+// WebhookPayload is the JSON body POSTed to every webhook.
+// A payload only ever holds alerts of one status, so receivers can route
+// "resolved" notifications separately.
+type WebhookPayload struct {
+	Status string    `json:"status"` // "firing" or "resolved"
+	Host   string    `json:"host"`   // Machine the alerts are about
+	Time   time.Time `json:"time"`   // When the newest snapshot in the payload was taken
+	Alerts []Alert   `json:"alerts"` // Metric, value, threshold, level and timing of each alert
+}
+
+// WebhookNotifier is a Sink that sends each snapshot's alert events to every
+// configured URL. Failed deliveries are retried with exponential backoff;
+// when an endpoint falls behind, queued payloads of the same status are
+// batched into one request, and once its queue is full the oldest payload
+// is dropped.
+type WebhookNotifier struct {
+	client   *http.Client
+	hostname string // Used when a snapshot doesn't carry host info
+	targets  []*webhookTarget
+
+	// ctx aborts retries and requests in flight when Close gives up waiting
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex // Guards closed against Observe racing Close
+	closed bool
+}
+
+// webhookTarget is one URL with its queue and delivery counters.
+type webhookTarget struct {
+	url       string
+	queue     chan WebhookPayload
+	delivered atomic.Uint64 // Requests accepted by the endpoint
+	failed    atomic.Uint64 // Payloads given up on after the last retry
+	dropped   atomic.Uint64 // Payloads pushed out of a full queue
+}
+
+// NewWebhookNotifier starts a delivery goroutine per URL. The request
+// timeout, retries and queue size come from config.
+func NewWebhookNotifier(urls []string) *WebhookNotifier {
+	hostname, _ := os.Hostname()
+	ctx, cancel := context.WithCancel(context.Background())
+	n := &WebhookNotifier{
+		client:   &http.Client{Timeout: config.WebhookTimeout},
+		hostname: hostname,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	for _, url := range urls {
+		target := &webhookTarget{url: url, queue: make(chan WebhookPayload, config.WebhookQueue)}
+		n.targets = append(n.targets, target)
+		n.wg.Add(1)
+		go n.deliver(target)
+	}
+	return n
+}
+
+// Observe implements Sink by queueing the snapshot's alert events.
+// It never blocks: a full queue loses its oldest payload instead.
+func (n *WebhookNotifier) Observe(stats SystemStats) {
+	if len(stats.AlertEvents) == 0 {
+		return
+	}
+
+	host := n.hostname
+	if stats.Host != nil && stats.Host.Hostname != "" {
+		host = stats.Host.Hostname
+	}
+
+	// SPLIT BY STATUS - resolved alerts get a notification of their own
+	var payloads []WebhookPayload
+	for _, status := range []string{notifyFiring, notifyResolved} {
+		var alerts []Alert
+		for _, alert := range stats.AlertEvents {
+			if (alert.State == AlertResolved) == (status == notifyResolved) {
+				alerts = append(alerts, alert)
+			}
+		}
+		if len(alerts) > 0 {
+			payloads = append(payloads, WebhookPayload{Status: status, Host: host, Time: stats.Time, Alerts: alerts})
+		}
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		return
+	}
+	for _, target := range n.targets {
+		for _, payload := range payloads {
+			if enqueueDropOldest(target.queue, payload) {
+				target.dropped.Add(1)
+				log.Printf("webhook %s: queue full, dropped the oldest notification", target.url)
+			}
+		}
+	}
+}
+
+// enqueueDropOldest adds item to queue without blocking, dropping the oldest
+// queued item if the queue is full, and reports whether it had to. The caller
+// must be the only sender, or the queue could fill up again before the retry.
+func enqueueDropOldest[T any](queue chan T, item T) (dropped bool) {
+	for {
+		select {
+		case queue <- item:
+			return dropped
+		default:
+			select {
+			case <-queue:
+				dropped = true
+			default: // Drained by the receiver in the meantime
+			}
+		}
+	}
+}
+
+// Close stops accepting notifications and waits up to the request timeout
+// for the queues to drain, then abandons whatever is left.
+func (n *WebhookNotifier) Close() {
+	n.mu.Lock()
+	if !n.closed {
+		n.closed = true
+		for _, target := range n.targets {
+			close(target.queue)
+		}
+	}
+	n.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(config.WebhookTimeout):
+		n.cancel() // Stop retrying and cut requests in flight short
+		<-done
+	}
+	n.cancel()
+}
+
+// deliver sends everything queued for one target until its queue is closed.
+func (n *WebhookNotifier) deliver(target *webhookTarget) {
+	defer n.wg.Done()
+
+	for payload := range target.queue {
+		// BATCHING - fold in whatever else queued up while we were busy
+		batch := []WebhookPayload{payload}
+	drain:
+		for len(batch) < cap(target.queue) {
+			select {
+			case next, ok := <-target.queue:
+				if !ok {
+					break drain
+				}
+				batch = append(batch, next)
+			default:
+				break drain
+			}
+		}
+
+		for _, payload := range mergePayloads(batch) {
+			if err := n.send(target.url, payload); err != nil {
+				target.failed.Add(1)
+				log.Printf("webhook %s: giving up on %d %s alerts: %v", target.url, len(payload.Alerts), payload.Status, err)
+				continue
+			}
+			target.delivered.Add(1)
+		}
+	}
+}
+
+// mergePayloads combines consecutive payloads of the same status, so
+// receivers still see alerts fire and resolve in the order they happened.
+func mergePayloads(batch []WebhookPayload) []WebhookPayload {
+	var merged []WebhookPayload
+	for _, payload := range batch {
+		if last := len(merged) - 1; last >= 0 && merged[last].Status == payload.Status {
+			merged[last].Alerts = append(merged[last].Alerts, payload.Alerts...)
+			merged[last].Time = payload.Time
+			continue
+		}
+		payload.Alerts = append([]Alert(nil), payload.Alerts...) // Appended to above, so copy
+		merged = append(merged, payload)
+	}
+	return merged
+}
+
+// send POSTs one payload, retrying failures with exponential backoff:
+// WebhookBackoff after the first failure, doubling up to WebhookMaxBackoff.
+// Client errors other than 408 and 429 are not retried - the request
+// itself is wrong and would fail again.
+func (n *WebhookNotifier) send(url string, payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	backoff := config.WebhookBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := n.post(url, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= config.WebhookRetries {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-n.ctx.Done():
+			return fmt.Errorf("%w (shutting down)", err)
+		}
+		backoff = min(backoff*2, config.WebhookMaxBackoff)
+	}
+}
+
+// post makes a single delivery attempt and reports whether a failure is
+// worth retrying.
+func (n *WebhookNotifier) post(url string, body []byte) (retry bool, err error) {
+	request, err := http.NewRequestWithContext(n.ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "go-hw-monitor")
+
+	response, err := n.client.Do(request)
+	if err != nil {
+		return true, err // Connection refused, timeout, ...
+	}
+	// Read the body to the end so the connection can be reused
+	io.Copy(io.Discard, response.Body)
+	response.Body.Close()
+
+	switch code := response.StatusCode; {
+	case code >= 200 && code < 300:
+		return false, nil
+	case code == http.StatusRequestTimeout, code == http.StatusTooManyRequests, code >= 500:
+		return true, fmt.Errorf("server answered %s", response.Status)
+	default:
+		return false, fmt.Errorf("server rejected the notification: %s", response.Status)
+	}
+}
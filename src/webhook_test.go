@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookServer records every payload it receives and answers with the
+// status codes in codes, then 200 once they run out.
+type webhookServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	payloads []WebhookPayload
+	codes    []int
+	block    chan struct{} // If set, requests wait until it is closed
+	started  chan struct{} // Receives a value as each blocked request arrives
+}
+
+func newWebhookServer(t *testing.T, codes ...int) *webhookServer {
+	t.Helper()
+	s := &webhookServer{codes: codes, started: make(chan struct{}, 10)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.block != nil {
+			s.started <- struct{}{}
+			<-s.block
+		}
+		var payload WebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Invalid payload: %v", err)
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.payloads = append(s.payloads, payload)
+		code := http.StatusOK
+		if len(s.codes) > 0 {
+			code, s.codes = s.codes[0], s.codes[1:]
+		}
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// received returns a copy of the payloads received so far.
+func (s *webhookServer) received() []WebhookPayload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]WebhookPayload(nil), s.payloads...)
+}
+
+// alertEvents returns a snapshot holding the given alert events.
+func alertEvents(events ...Alert) SystemStats {
+	stats := SystemStats{Time: time.Unix(1700000000, 0).UTC(), Host: &HostInfo{Hostname: "web-1"}}
+	stats.AlertEvents = events
+	return stats
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	original := config
+	defer func() { config = original }()
+	config.WebhookBackoff = time.Millisecond
+	config.WebhookMaxBackoff = 4 * time.Millisecond
+	config.WebhookTimeout = 2 * time.Second
+
+	firing := Alert{Metric: "cpu.usage_percent", Level: LevelCritical, State: AlertActive, Value: 97, Threshold: 95}
+	resolved := Alert{Metric: "disk.used_percent", Instance: "/", Level: LevelWarning, State: AlertResolved, Value: 70, Threshold: 85}
+
+	t.Run("FiringAndResolved", func(t *testing.T) {
+		// Arrange
+		server := newWebhookServer(t)
+		notifier := NewWebhookNotifier([]string{server.URL})
+
+		// Act
+		notifier.Observe(alertEvents(firing, resolved))
+		notifier.Observe(alertEvents()) // Nothing happened - nothing is sent
+		notifier.Close()
+
+		// Assert - one notification per status, firing first
+		payloads := server.received()
+		if len(payloads) != 2 {
+			t.Fatalf("Expected 2 notifications, got %d", len(payloads))
+		}
+		first, second := payloads[0], payloads[1]
+		if first.Status != notifyFiring || first.Host != "web-1" || !first.Time.Equal(alertEvents().Time) {
+			t.Errorf("Unexpected firing notification %+v", first)
+		}
+		if len(first.Alerts) != 1 || first.Alerts[0].Metric != "cpu.usage_percent" || first.Alerts[0].Level != LevelCritical || first.Alerts[0].Value != 97 || first.Alerts[0].Threshold != 95 {
+			t.Errorf("Expected the cpu alert with its value and threshold, got %+v", first.Alerts)
+		}
+		if second.Status != notifyResolved || len(second.Alerts) != 1 || second.Alerts[0].Instance != "/" {
+			t.Errorf("Unexpected resolved notification %+v", second)
+		}
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		server := newWebhookServer(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+		notifier := NewWebhookNotifier([]string{server.URL})
+
+		notifier.Observe(alertEvents(firing))
+		notifier.Close()
+
+		if got := len(server.received()); got != 3 {
+			t.Errorf("Expected 2 failures and a retry that succeeds, got %d requests", got)
+		}
+		if target := notifier.targets[0]; target.delivered.Load() != 1 || target.failed.Load() != 0 {
+			t.Errorf("Expected 1 delivered, got %d delivered and %d failed", target.delivered.Load(), target.failed.Load())
+		}
+	})
+
+	t.Run("GivesUp", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			codes    []int
+			requests int
+		}{
+			{name: "AfterRetries", codes: []int{500, 500, 500, 500}, requests: 3},
+			{name: "Rejected", codes: []int{http.StatusBadRequest}, requests: 1}, // Not worth retrying
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				config.WebhookRetries = 2
+				defer func() { config.WebhookRetries = original.WebhookRetries }()
+				server := newWebhookServer(t, tt.codes...)
+				notifier := NewWebhookNotifier([]string{server.URL})
+
+				notifier.Observe(alertEvents(firing))
+				notifier.Close()
+
+				if got := len(server.received()); got != tt.requests {
+					t.Errorf("Expected %d requests, got %d", tt.requests, got)
+				}
+				if failed := notifier.targets[0].failed.Load(); failed != 1 {
+					t.Errorf("Expected the notification to be given up on, got %d failed", failed)
+				}
+			})
+		}
+	})
+
+	t.Run("BatchesAndDrops", func(t *testing.T) {
+		// Arrange - the endpoint hangs on the first request
+		config.WebhookQueue = 3
+		defer func() { config.WebhookQueue = original.WebhookQueue }()
+		server := newWebhookServer(t)
+		server.block = make(chan struct{})
+		notifier := NewWebhookNotifier([]string{server.URL})
+
+		// Act - the first notification is in flight, then five more queue up
+		notifier.Observe(alertEvents(firing))
+		<-server.started
+		start := time.Now()
+		for range 5 {
+			notifier.Observe(alertEvents(firing))
+		}
+		notifier.Observe(alertEvents(resolved))
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Observe blocked for %s on a hung endpoint", elapsed)
+		}
+		close(server.block)
+		notifier.Close()
+
+		// Assert - 3 of the 6 were dropped; the rest went out as one batch per status
+		payloads := server.received()
+		if dropped := notifier.targets[0].dropped.Load(); dropped != 3 {
+			t.Errorf("Expected 3 dropped notifications, got %d", dropped)
+		}
+		if len(payloads) != 3 {
+			t.Fatalf("Expected the first notification and 2 batches, got %d requests", len(payloads))
+		}
+		if payloads[1].Status != notifyFiring || len(payloads[1].Alerts) != 2 {
+			t.Errorf("Expected 2 firing alerts batched together, got %+v", payloads[1])
+		}
+		if payloads[2].Status != notifyResolved || len(payloads[2].Alerts) != 1 {
+			t.Errorf("Expected the resolved alert last, got %+v", payloads[2])
+		}
+	})
+
+	t.Run("ObserveAfterClose", func(t *testing.T) {
+		server := newWebhookServer(t)
+		notifier := NewWebhookNotifier([]string{server.URL})
+		notifier.Close()
+
+		notifier.Observe(alertEvents(firing)) // The sampler may still finish a collection
+
+		if got := len(server.received()); got != 0 {
+			t.Errorf("Expected nothing sent after Close, got %d requests", got)
+		}
+	})
+}